@@ -0,0 +1,388 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+// this file contains handwritten additions to the generated code, adding a client for the
+// Assistants v2 surface (assistants, threads, messages and runs), including a streaming helper
+// for consuming a run's event stream and resuming it after submitting tool outputs.
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// assistantsBetaHeaderName/assistantsBetaHeaderValue mark every request as targeting the
+// Assistants v2 surface; the service rejects Assistants requests that omit this header.
+const (
+	assistantsBetaHeaderName  = "OpenAI-Beta"
+	assistantsBetaHeaderValue = "assistants=v2"
+)
+
+// AssistantsClient is a client for the Azure OpenAI / OpenAI Assistants v2 API: assistants,
+// threads, messages and runs.
+//
+// NOTE: This should be created using [azopenai.Client.NewAssistantsClient].
+type AssistantsClient struct {
+	client *Client
+}
+
+// NewAssistantsClient returns an AssistantsClient that shares client's pipeline, endpoint and
+// authentication, scoped to the Assistants v2 surface.
+func (client *Client) NewAssistantsClient() *AssistantsClient {
+	return &AssistantsClient{client: client}
+}
+
+// Assistant represents a configured assistant: a model plus instructions, tools and metadata.
+type Assistant struct {
+	ID           *string                                       `json:"id,omitempty"`
+	Model        *string                                       `json:"model,omitempty"`
+	Name         *string                                       `json:"name,omitempty"`
+	Instructions *string                                       `json:"instructions,omitempty"`
+	Tools        []ChatCompletionsToolDefinitionClassification `json:"tools,omitempty"`
+}
+
+// CreateAssistantBody contains the parameters for AssistantsClient.CreateAssistant.
+type CreateAssistantBody struct {
+	Model        string                                        `json:"model"`
+	Name         *string                                       `json:"name,omitempty"`
+	Instructions *string                                       `json:"instructions,omitempty"`
+	Tools        []ChatCompletionsToolDefinitionClassification `json:"tools,omitempty"`
+}
+
+// CreateAssistant creates a new Assistant.
+func (ac *AssistantsClient) CreateAssistant(ctx context.Context, body CreateAssistantBody) (Assistant, error) {
+	var result Assistant
+	return result, ac.postJSON(ctx, "/assistants", body, &result)
+}
+
+// Thread represents a conversation thread: an ordered sequence of messages that one or more runs
+// can be executed against.
+type Thread struct {
+	ID *string `json:"id,omitempty"`
+}
+
+// CreateThread creates a new, empty Thread.
+func (ac *AssistantsClient) CreateThread(ctx context.Context) (Thread, error) {
+	var result Thread
+	return result, ac.postJSON(ctx, "/threads", struct{}{}, &result)
+}
+
+// ThreadMessage represents a single message on a Thread.
+type ThreadMessage struct {
+	ID      *string `json:"id,omitempty"`
+	Role    *string `json:"role,omitempty"`
+	Content *string `json:"content,omitempty"`
+}
+
+// CreateMessageBody contains the parameters for AssistantsClient.CreateMessage.
+type CreateMessageBody struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+// CreateMessage appends a new message to threadID.
+func (ac *AssistantsClient) CreateMessage(ctx context.Context, threadID string, body CreateMessageBody) (ThreadMessage, error) {
+	var result ThreadMessage
+	return result, ac.postJSON(ctx, fmt.Sprintf("/threads/%s/messages", threadID), body, &result)
+}
+
+// Run represents a single execution of an assistant against a thread.
+type Run struct {
+	ID             *string            `json:"id,omitempty"`
+	ThreadID       *string            `json:"thread_id,omitempty"`
+	Status         *string            `json:"status,omitempty"`
+	RequiredAction *RunRequiredAction `json:"required_action,omitempty"`
+}
+
+// RunRequiredAction describes the tool outputs a run is blocked on before it can continue.
+type RunRequiredAction struct {
+	SubmitToolOutputs *struct {
+		ToolCalls []ChatCompletionsToolCallClassification `json:"tool_calls,omitempty"`
+	} `json:"submit_tool_outputs,omitempty"`
+}
+
+// UnmarshalJSON implements the json.Unmarshaller interface for type RunRequiredAction. ToolCalls is
+// polymorphic (ChatCompletionsToolCallClassification), so it can't be populated by the default JSON
+// decoder and needs the same discriminated unmarshaling the generated code uses elsewhere.
+func (r *RunRequiredAction) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		SubmitToolOutputs *struct {
+			ToolCalls json.RawMessage `json:"tool_calls"`
+		} `json:"submit_tool_outputs"`
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+
+	if wire.SubmitToolOutputs == nil {
+		return nil
+	}
+
+	toolCalls, err := unmarshalChatCompletionsToolCallClassificationArray(wire.SubmitToolOutputs.ToolCalls)
+	if err != nil {
+		return err
+	}
+
+	r.SubmitToolOutputs = &struct {
+		ToolCalls []ChatCompletionsToolCallClassification `json:"tool_calls,omitempty"`
+	}{ToolCalls: toolCalls}
+
+	return nil
+}
+
+// CreateRunBody contains the parameters for AssistantsClient.CreateRun.
+type CreateRunBody struct {
+	AssistantID string `json:"assistant_id"`
+}
+
+// CreateRun starts a new Run of assistantID against threadID.
+func (ac *AssistantsClient) CreateRun(ctx context.Context, threadID string, body CreateRunBody) (Run, error) {
+	var result Run
+	return result, ac.postJSON(ctx, fmt.Sprintf("/threads/%s/runs", threadID), body, &result)
+}
+
+// RunStreamEvent is a single typed event from a run's SSE stream, parsed by runEventReader from
+// the stream's "event:"/"data:" lines.
+type RunStreamEvent struct {
+	// Event is the SSE event name, e.g. "thread.run.requires_action", "thread.message.delta" or
+	// "thread.run.step.delta".
+	Event string
+
+	// Data is the event's raw JSON payload, whose shape depends on Event.
+	Data json.RawMessage
+}
+
+// RunStream is returned by CreateAndStreamRun and SubmitToolOutputsAndStream, giving access to
+// the run's typed SSE event stream.
+//
+// Callers that stop reading before the stream's terminal event - for example, to call
+// SubmitToolOutputsAndStream after seeing a thread.run.requires_action event - must call Close to
+// release the underlying connection.
+type RunStream struct {
+	reader interface {
+		Read() (RunStreamEvent, error)
+		Close() error
+	}
+}
+
+// Read returns the next event in the stream, or an io.EOF-wrapping error once the stream's
+// terminal event has been consumed.
+func (s RunStream) Read() (RunStreamEvent, error) {
+	return s.reader.Read()
+}
+
+// Close releases the underlying connection. It's safe to call after the stream has already been
+// read to completion.
+func (s RunStream) Close() error {
+	return s.reader.Close()
+}
+
+// CreateAndStreamRun starts a new Run and returns a RunStream for consuming its SSE event
+// stream, dispatching typed events (thread.run.requires_action, thread.message.delta,
+// thread.run.step.delta, etc.) as they arrive. As with GetChatCompletionsStream, the stream
+// automatically reconnects on a transport error if ClientOptions.Stream.AutoReconnect is set.
+func (ac *AssistantsClient) CreateAndStreamRun(ctx context.Context, threadID string, body CreateRunBody) (RunStream, error) {
+	path := fmt.Sprintf("/threads/%s/runs", threadID)
+	streamBody := struct {
+		CreateRunBody
+		Stream bool `json:"stream"`
+	}{CreateRunBody: body, Stream: true}
+
+	resp, err := ac.streamJSON(ctx, path, streamBody)
+	if err != nil {
+		return RunStream{}, err
+	}
+
+	var stream io.ReadCloser = resp.Body
+	if ac.client.streamOptions.AutoReconnect {
+		stream = newReconnectingReader(ctx, resp.Body, ac.client.streamOptions, func(reopenCtx context.Context, lastEventID string) (io.ReadCloser, error) {
+			reopenResp, err := ac.streamJSON(reopenCtx, path, streamBody, lastEventID)
+			if err != nil {
+				return nil, err
+			}
+			return reopenResp.Body, nil
+		})
+	}
+
+	return RunStream{reader: newRunEventReader(stream)}, nil
+}
+
+// SubmitToolOutput is a single tool result submitted back to a run that's paused on
+// thread.run.requires_action, matched to the originating call by ToolCallID.
+type SubmitToolOutput struct {
+	ToolCallID string `json:"tool_call_id"`
+	Output     string `json:"output"`
+}
+
+// SubmitToolOutputsAndStream submits toolOutputs for runID on threadID and resumes streaming its
+// SSE event stream, picking up where CreateAndStreamRun left off. As with CreateAndStreamRun, the
+// stream automatically reconnects on a transport error if ClientOptions.Stream.AutoReconnect is
+// set; a reconnect resubmits the same tool outputs, which the service tolerates as a duplicate
+// submission for the run's already-recorded required action.
+func (ac *AssistantsClient) SubmitToolOutputsAndStream(ctx context.Context, threadID, runID string, toolOutputs []SubmitToolOutput) (RunStream, error) {
+	path := fmt.Sprintf("/threads/%s/runs/%s/submit_tool_outputs", threadID, runID)
+	body := struct {
+		ToolOutputs []SubmitToolOutput `json:"tool_outputs"`
+		Stream      bool               `json:"stream"`
+	}{ToolOutputs: toolOutputs, Stream: true}
+
+	resp, err := ac.streamJSON(ctx, path, body)
+	if err != nil {
+		return RunStream{}, err
+	}
+
+	var stream io.ReadCloser = resp.Body
+	if ac.client.streamOptions.AutoReconnect {
+		stream = newReconnectingReader(ctx, resp.Body, ac.client.streamOptions, func(reopenCtx context.Context, lastEventID string) (io.ReadCloser, error) {
+			reopenResp, err := ac.streamJSON(reopenCtx, path, body, lastEventID)
+			if err != nil {
+				return nil, err
+			}
+			return reopenResp.Body, nil
+		})
+	}
+
+	return RunStream{reader: newRunEventReader(stream)}, nil
+}
+
+// runEventReader parses a run's SSE stream into RunStreamEvent values, unlike the generic
+// newEventReader used for chat/completions streams, it captures the "event:" line alongside
+// "data:" so RunStreamEvent.Event is actually populated.
+type runEventReader struct {
+	body    io.ReadCloser
+	scanner *bufio.Scanner
+	done    bool
+}
+
+// newRunEventReader creates a new instance of runEventReader reading from body.
+func newRunEventReader(body io.ReadCloser) *runEventReader {
+	return &runEventReader{body: body, scanner: bufio.NewScanner(body)}
+}
+
+// Read returns the next event in the stream, or an error wrapping io.EOF once the stream's
+// terminal "data: [DONE]" event (or the underlying body) has been exhausted.
+func (r *runEventReader) Read() (RunStreamEvent, error) {
+	if r.done {
+		return RunStreamEvent{}, io.EOF
+	}
+
+	var event string
+	var data strings.Builder
+
+	for r.scanner.Scan() {
+		line := r.scanner.Text()
+
+		switch {
+		case line == "":
+			if event == "" && data.Len() == 0 {
+				continue
+			}
+			if data.String() == "[DONE]" {
+				r.done = true
+				return RunStreamEvent{}, io.EOF
+			}
+			return RunStreamEvent{Event: event, Data: json.RawMessage(data.String())}, nil
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			if data.Len() > 0 {
+				data.WriteByte('\n')
+			}
+			data.WriteString(strings.TrimSpace(strings.TrimPrefix(line, "data:")))
+		}
+	}
+
+	if err := r.scanner.Err(); err != nil {
+		return RunStreamEvent{}, err
+	}
+
+	r.done = true
+	return RunStreamEvent{}, io.EOF
+}
+
+// Close releases the underlying connection.
+func (r *runEventReader) Close() error {
+	return r.body.Close()
+}
+
+// postJSON POSTs body as JSON to path and unmarshals the response into out.
+func (ac *AssistantsClient) postJSON(ctx context.Context, path string, body any, out any) error {
+	resp, err := ac.doJSON(ctx, path, body)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	return runtime.UnmarshalAsJSON(resp, out)
+}
+
+// streamJSON POSTs body as JSON to path, skipping body download so the caller can read the
+// response as an SSE stream, optionally carrying a Last-Event-ID header so the server can resume
+// after lastEventID, if given.
+func (ac *AssistantsClient) streamJSON(ctx context.Context, path string, body any, lastEventID ...string) (*http.Response, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, ac.client.formatURL(path, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runtime.MarshalAsJSON(req, body); err != nil {
+		return nil, err
+	}
+
+	req.Raw().Header.Set(assistantsBetaHeaderName, assistantsBetaHeaderValue)
+
+	runtime.SkipBodyDownload(req)
+
+	if len(lastEventID) > 0 && lastEventID[0] != "" {
+		req.Raw().Header.Set("Last-Event-ID", lastEventID[0])
+	}
+
+	resp, err := ac.client.internal.Pipeline().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		_ = resp.Body.Close()
+		return nil, runtime.NewResponseError(resp)
+	}
+
+	return resp, nil
+}
+
+// doJSON POSTs body as JSON to path and returns the raw response.
+func (ac *AssistantsClient) doJSON(ctx context.Context, path string, body any) (*http.Response, error) {
+	req, err := runtime.NewRequest(ctx, http.MethodPost, ac.client.formatURL(path, nil))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := runtime.MarshalAsJSON(req, body); err != nil {
+		return nil, err
+	}
+
+	req.Raw().Header.Set(assistantsBetaHeaderName, assistantsBetaHeaderValue)
+
+	resp, err := ac.client.internal.Pipeline().Do(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if !runtime.HasStatusCode(resp, http.StatusOK) {
+		_ = resp.Body.Close()
+		return nil, runtime.NewResponseError(resp)
+	}
+
+	return resp, nil
+}
@@ -0,0 +1,89 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestRunEventReaderParsesEventAndData(t *testing.T) {
+	body := "event: thread.run.requires_action\n" +
+		"data: {\"id\":\"run_1\"}\n\n" +
+		"event: thread.message.delta\n" +
+		"data: {\"delta\":1}\n\n" +
+		"data: [DONE]\n\n"
+
+	r := newRunEventReader(io.NopCloser(strings.NewReader(body)))
+
+	first, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if first.Event != "thread.run.requires_action" {
+		t.Fatalf("Event = %q, want %q", first.Event, "thread.run.requires_action")
+	}
+	if string(first.Data) != `{"id":"run_1"}` {
+		t.Fatalf("Data = %q", first.Data)
+	}
+
+	second, err := r.Read()
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	if second.Event != "thread.message.delta" {
+		t.Fatalf("Event = %q, want %q", second.Event, "thread.message.delta")
+	}
+
+	if _, err := r.Read(); err != io.EOF {
+		t.Fatalf("Read at end = %v, want io.EOF", err)
+	}
+}
+
+func TestRunRequiredActionUnmarshalJSON(t *testing.T) {
+	data := []byte(`{
+		"submit_tool_outputs": {
+			"tool_calls": [
+				{"id": "call_1", "type": "function", "function": {"name": "get_weather", "arguments": "{\"city\":\"Seattle\"}"}}
+			]
+		}
+	}`)
+
+	var action RunRequiredAction
+	if err := action.UnmarshalJSON(data); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+
+	if action.SubmitToolOutputs == nil {
+		t.Fatal("SubmitToolOutputs = nil, want non-nil")
+	}
+	if len(action.SubmitToolOutputs.ToolCalls) != 1 {
+		t.Fatalf("ToolCalls = %v, want 1 entry", action.SubmitToolOutputs.ToolCalls)
+	}
+
+	call, ok := action.SubmitToolOutputs.ToolCalls[0].(*ChatCompletionsFunctionToolCall)
+	if !ok {
+		t.Fatalf("ToolCalls[0] = %T, want *ChatCompletionsFunctionToolCall", action.SubmitToolOutputs.ToolCalls[0])
+	}
+	if call.ID == nil || *call.ID != "call_1" {
+		t.Fatalf("ID = %v, want call_1", call.ID)
+	}
+	if call.Function == nil || call.Function.Name == nil || *call.Function.Name != "get_weather" {
+		t.Fatalf("Function.Name = %v, want get_weather", call.Function)
+	}
+}
+
+func TestRunRequiredActionUnmarshalJSONNoAction(t *testing.T) {
+	var action RunRequiredAction
+	if err := action.UnmarshalJSON([]byte(`{}`)); err != nil {
+		t.Fatalf("UnmarshalJSON: %v", err)
+	}
+	if action.SubmitToolOutputs != nil {
+		t.Fatalf("SubmitToolOutputs = %v, want nil", action.SubmitToolOutputs)
+	}
+}
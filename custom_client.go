@@ -15,6 +15,7 @@ package azopenai
 import (
 	"context"
 	"encoding/json"
+	"io"
 	"net/http"
 	"net/url"
 
@@ -33,8 +34,69 @@ const (
 // ClientOptions contains optional settings for Client.
 type ClientOptions struct {
 	azcore.ClientOptions
+
+	// Backend identifies which OpenAI-compatible API flavor a Client targets. The constructors do
+	// not read this field: NewClient and NewClientWithKeyCredential always target BackendAzure,
+	// NewClientForOpenAI always targets BackendOpenAI, and NewClientForOpenAICompatible always
+	// targets BackendCompatible.
+	Backend Backend
+
+	// AuthHeaderName overrides the header used to carry the credential. Defaults to "api-key"
+	// for NewClientWithKeyCredential and "authorization" for NewClientForOpenAI and
+	// NewClientForOpenAICompatible.
+	AuthHeaderName string
+
+	// AuthHeaderPrefix is prepended to the credential's value in AuthHeaderName, for example
+	// "Bearer ". Defaults to "Bearer " when AuthHeaderName is "authorization", and empty otherwise.
+	AuthHeaderPrefix string
+
+	// ModelHeaderName, when set, causes the deployment/model name to also be sent as a request
+	// header with this name, for OpenAI-compatible servers that expect it there instead of (or
+	// in addition to) the URL path. Only GetChatCompletionsStream and GetCompletionsStream send
+	// this header; other operations do not. Has no effect for BackendAzure.
+	ModelHeaderName string
+
+	// DisableTempAPIVersionPolicy disables the policy that force-sets the api-version query
+	// parameter on every request. Third-party OpenAI-compatible servers don't understand this
+	// parameter and some reject requests that contain it.
+	DisableTempAPIVersionPolicy bool
+
+	// Retry configures rateLimitRetryPolicy, which is installed by default and retries 429/503
+	// responses honoring the server's Retry-After and x-ratelimit-reset-* headers. azcore's own
+	// built-in retry policy is disabled (unless the embedded azcore.ClientOptions.Retry.MaxRetries
+	// is set explicitly) so it doesn't compound with this one.
+	Retry RetryOptions
+
+	// PreflightTokenCheck, when true, makes Client.GetChatCompletionsStream and the explicit
+	// Client.GetChatCompletionsWithPreflightCheck helper estimate prompt tokens plus MaxTokens
+	// before sending the request, returning a *TokenLimitError instead of round-tripping to the
+	// server when the model's context window would be exceeded. Has no effect for models outside
+	// the gpt-3.5-turbo, gpt-4, gpt-4-turbo, gpt-4o and gpt-4o-mini families (matched by name
+	// prefix), whose context windows are unknown. The generated Client.GetChatCompletions is unaffected;
+	// use GetChatCompletionsWithPreflightCheck to opt that call path in explicitly.
+	PreflightTokenCheck bool
+
+	// Stream configures the resilience behavior - automatic reconnection, reconnect limits and
+	// backoff - applied by Client.GetChatCompletionsStream and Client.GetCompletionsStream.
+	Stream StreamOptions
 }
 
+// Backend identifies the flavor of OpenAI-compatible API that a Client targets, controlling how
+// Client.formatURL shapes request URLs.
+type Backend int
+
+const (
+	// BackendAzure targets the Azure OpenAI Service, using /openai/deployments/{id} paths.
+	BackendAzure Backend = iota
+
+	// BackendOpenAI targets the public OpenAI API.
+	BackendOpenAI
+
+	// BackendCompatible targets a third-party OpenAI-compatible server, such as Ollama, vLLM,
+	// LM Studio, Together AI or Groq.
+	BackendCompatible
+)
+
 const apiVersion = "2024-08-01-preview"
 
 // NewClient creates a new instance of Client that connects to an Azure OpenAI endpoint.
@@ -50,8 +112,11 @@ func NewClient(endpoint string, credential azcore.TokenCredential, options *Clie
 		InsecureAllowCredentialWithHTTP: allowInsecure(options),
 	})
 
+	rateLimitPolicy := newRateLimitRetryPolicy(options.Retry)
+	disableBuiltInRetry(options)
+
 	azcoreClient, err := azcore.NewClient(clientName, version, runtime.PipelineOptions{
-		PerRetry: []policy.Policy{authPolicy, tempAPIVersionPolicy{}},
+		PerRetry: perRetryPolicies(options, authPolicy, rateLimitPolicy),
 	}, &options.ClientOptions)
 
 	if err != nil {
@@ -61,8 +126,11 @@ func NewClient(endpoint string, credential azcore.TokenCredential, options *Clie
 	return &Client{
 		internal: azcoreClient,
 		clientData: clientData{
-			endpoint: endpoint,
-			azure:    true,
+			endpoint:            endpoint,
+			backend:             BackendAzure,
+			rateLimitPolicy:     rateLimitPolicy,
+			preflightTokenCheck: options.PreflightTokenCheck,
+			streamOptions:       options.Stream,
 		},
 	}, nil
 }
@@ -76,12 +144,26 @@ func NewClientWithKeyCredential(endpoint string, credential *azcore.KeyCredentia
 		options = &ClientOptions{}
 	}
 
-	authPolicy := runtime.NewKeyCredentialPolicy(credential, "api-key", &runtime.KeyCredentialPolicyOptions{
+	headerName := options.AuthHeaderName
+	if headerName == "" {
+		headerName = "api-key"
+	}
+
+	headerPrefix := options.AuthHeaderPrefix
+	if headerPrefix == "" && headerName == "authorization" {
+		headerPrefix = "Bearer "
+	}
+
+	authPolicy := runtime.NewKeyCredentialPolicy(credential, headerName, &runtime.KeyCredentialPolicyOptions{
+		Prefix:                          headerPrefix,
 		InsecureAllowCredentialWithHTTP: allowInsecure(options),
 	})
 
+	rateLimitPolicy := newRateLimitRetryPolicy(options.Retry)
+	disableBuiltInRetry(options)
+
 	azcoreClient, err := azcore.NewClient(clientName, version, runtime.PipelineOptions{
-		PerRetry: []policy.Policy{authPolicy, tempAPIVersionPolicy{}},
+		PerRetry: perRetryPolicies(options, authPolicy, rateLimitPolicy),
 	}, &options.ClientOptions)
 	if err != nil {
 		return nil, err
@@ -90,8 +172,11 @@ func NewClientWithKeyCredential(endpoint string, credential *azcore.KeyCredentia
 	return &Client{
 		internal: azcoreClient,
 		clientData: clientData{
-			endpoint: endpoint,
-			azure:    true,
+			endpoint:            endpoint,
+			backend:             BackendAzure,
+			rateLimitPolicy:     rateLimitPolicy,
+			preflightTokenCheck: options.PreflightTokenCheck,
+			streamOptions:       options.Stream,
 		},
 	}, nil
 }
@@ -105,18 +190,35 @@ func NewClientForOpenAI(endpoint string, credential *azcore.KeyCredential, optio
 		options = &ClientOptions{}
 	}
 
-	kp := runtime.NewKeyCredentialPolicy(credential, "authorization", &runtime.KeyCredentialPolicyOptions{
-		Prefix:                          "Bearer ",
-		InsecureAllowCredentialWithHTTP: allowInsecure(options),
-	})
+	azcoreClient, rateLimitPolicy, err := newOpenAICompatibleClient(credential, options)
+	if err != nil {
+		return nil, err
+	}
 
-	azcoreClient, err := azcore.NewClient(clientName, version, runtime.PipelineOptions{
-		PerRetry: []policy.Policy{
-			kp,
-			newOpenAIPolicy(),
+	return &Client{
+		internal: azcoreClient,
+		clientData: clientData{
+			endpoint:            endpoint,
+			backend:             BackendOpenAI,
+			rateLimitPolicy:     rateLimitPolicy,
+			preflightTokenCheck: options.PreflightTokenCheck,
+			streamOptions:       options.Stream,
 		},
-	}, &options.ClientOptions)
+	}, nil
+}
+
+// NewClientForOpenAICompatible creates a new instance of Client which connects to a third-party,
+// OpenAI-compatible server, such as Ollama, vLLM, LM Studio, Together AI or Groq.
+//   - endpoint - the server's OpenAI-compatible base URL, for example: http://localhost:11434/v1
+//   - credential - used to authorize requests with an API Key credential. Pass an empty
+//     [azcore.KeyCredential] for servers that don't require authentication.
+//   - options - client options, pass nil to accept the default values.
+func NewClientForOpenAICompatible(endpoint string, credential *azcore.KeyCredential, options *ClientOptions) (*Client, error) {
+	if options == nil {
+		options = &ClientOptions{}
+	}
 
+	azcoreClient, rateLimitPolicy, err := newOpenAICompatibleClient(credential, options)
 	if err != nil {
 		return nil, err
 	}
@@ -124,12 +226,58 @@ func NewClientForOpenAI(endpoint string, credential *azcore.KeyCredential, optio
 	return &Client{
 		internal: azcoreClient,
 		clientData: clientData{
-			endpoint: endpoint,
-			azure:    false,
+			endpoint:            endpoint,
+			backend:             BackendCompatible,
+			rateLimitPolicy:     rateLimitPolicy,
+			preflightTokenCheck: options.PreflightTokenCheck,
+			streamOptions:       options.Stream,
 		},
 	}, nil
 }
 
+// newOpenAICompatibleClient builds the azcore.Client shared by NewClientForOpenAI and
+// NewClientForOpenAICompatible: both talk to non-Azure, OpenAI-shaped REST APIs and differ only
+// in the URL path shaping applied later, in Client.formatURL.
+func newOpenAICompatibleClient(credential *azcore.KeyCredential, options *ClientOptions) (*azcore.Client, *rateLimitRetryPolicy, error) {
+	headerName := options.AuthHeaderName
+	if headerName == "" {
+		headerName = "authorization"
+	}
+
+	headerPrefix := options.AuthHeaderPrefix
+	if options.AuthHeaderPrefix == "" && headerName == "authorization" {
+		headerPrefix = "Bearer "
+	}
+
+	kp := runtime.NewKeyCredentialPolicy(credential, headerName, &runtime.KeyCredentialPolicyOptions{
+		Prefix:                          headerPrefix,
+		InsecureAllowCredentialWithHTTP: allowInsecure(options),
+	})
+
+	rateLimitPolicy := newRateLimitRetryPolicy(options.Retry)
+	disableBuiltInRetry(options)
+
+	perRetry := []policy.Policy{kp, newOpenAIPolicy(), rateLimitPolicy}
+	if options.ModelHeaderName != "" {
+		perRetry = append(perRetry, &modelHeaderPolicy{headerName: options.ModelHeaderName})
+	}
+
+	azcoreClient, err := azcore.NewClient(clientName, version, runtime.PipelineOptions{
+		PerRetry: perRetry,
+	}, &options.ClientOptions)
+
+	return azcoreClient, rateLimitPolicy, err
+}
+
+// perRetryPolicies builds the PerRetry policy chain used by the Azure-targeting constructors,
+// honoring ClientOptions.DisableTempAPIVersionPolicy.
+func perRetryPolicies(options *ClientOptions, authPolicy policy.Policy, rateLimitPolicy policy.Policy) []policy.Policy {
+	if options.DisableTempAPIVersionPolicy {
+		return []policy.Policy{authPolicy, rateLimitPolicy}
+	}
+	return []policy.Policy{authPolicy, tempAPIVersionPolicy{}, rateLimitPolicy}
+}
+
 // openAIPolicy is an internal pipeline policy to remove the api-version query parameter
 type openAIPolicy struct{}
 
@@ -184,70 +332,133 @@ func (o streamCompletionsOptions) MarshalJSON() ([]byte, error) {
 // If the operation fails it returns an *azcore.ResponseError type.
 //   - options - GetCompletionsOptions contains the optional parameters for the Client.GetCompletions method.
 func (client *Client) GetCompletionsStream(ctx context.Context, body CompletionsOptions, options *GetCompletionsStreamOptions) (GetCompletionsStreamResponse, error) {
-	req, err := client.getCompletionsCreateRequest(ctx, body, &GetCompletionsOptions{})
+	if deployment := getDeployment(body); deployment != nil {
+		ctx = withModelHeaderValue(ctx, *deployment)
+	}
 
+	resp, err := client.doCompletionsStreamRequest(ctx, body)
 	if err != nil {
 		return GetCompletionsStreamResponse{}, err
 	}
 
+	var stream io.ReadCloser = resp.Body
+	if client.streamOptions.AutoReconnect {
+		stream = newReconnectingReader(ctx, resp.Body, client.streamOptions, func(reopenCtx context.Context, lastEventID string) (io.ReadCloser, error) {
+			reopenResp, err := client.doCompletionsStreamRequest(reopenCtx, body, lastEventID)
+			if err != nil {
+				return nil, err
+			}
+			return reopenResp.Body, nil
+		})
+	}
+
+	return GetCompletionsStreamResponse{
+		CompletionsStream: newEventReader[Completions](stream),
+	}, nil
+}
+
+// doCompletionsStreamRequest builds and sends a single streaming GetCompletionsStream request,
+// optionally carrying a Last-Event-ID header so the server can resume after lastEventID, if given.
+func (client *Client) doCompletionsStreamRequest(ctx context.Context, body CompletionsOptions, lastEventID ...string) (*http.Response, error) {
+	req, err := client.getCompletionsCreateRequest(ctx, body, &GetCompletionsOptions{})
+	if err != nil {
+		return nil, err
+	}
+
 	if err := runtime.MarshalAsJSON(req, streamCompletionsOptions{
 		any:    body,
 		Stream: true,
 	}); err != nil {
-		return GetCompletionsStreamResponse{}, err
+		return nil, err
 	}
 
 	runtime.SkipBodyDownload(req)
 
-	resp, err := client.internal.Pipeline().Do(req)
+	if len(lastEventID) > 0 && lastEventID[0] != "" {
+		req.Raw().Header.Set("Last-Event-ID", lastEventID[0])
+	}
 
+	resp, err := client.internal.Pipeline().Do(req)
 	if err != nil {
-		return GetCompletionsStreamResponse{}, err
+		return nil, err
 	}
 
 	if !runtime.HasStatusCode(resp, http.StatusOK) {
 		_ = resp.Body.Close()
-		return GetCompletionsStreamResponse{}, runtime.NewResponseError(resp)
+		return nil, runtime.NewResponseError(resp)
 	}
 
-	return GetCompletionsStreamResponse{
-		CompletionsStream: newEventReader[Completions](resp.Body),
-	}, nil
+	return resp, nil
 }
 
 // GetChatCompletionsStream - Return the chat completions for a given prompt as a sequence of events.
 // If the operation fails it returns an *azcore.ResponseError type.
 //   - options - GetCompletionsOptions contains the optional parameters for the Client.GetCompletions method.
 func (client *Client) GetChatCompletionsStream(ctx context.Context, body ChatCompletionsOptions, options *GetChatCompletionsStreamOptions) (GetChatCompletionsStreamResponse, error) {
-	req, err := client.getChatCompletionsCreateRequest(ctx, body, &GetChatCompletionsOptions{})
+	if client.preflightTokenCheck {
+		if err := checkPreflightTokenLimit(modelForPreflight(body), chatMessagesForTokenCount(body.Messages), toolsForTokenCount(body.Tools), maxTokensForPreflight(body)); err != nil {
+			return GetChatCompletionsStreamResponse{}, err
+		}
+	}
+
+	if deployment := getDeployment(body); deployment != nil {
+		ctx = withModelHeaderValue(ctx, *deployment)
+	}
 
+	resp, err := client.doChatCompletionsStreamRequest(ctx, body)
 	if err != nil {
 		return GetChatCompletionsStreamResponse{}, err
 	}
 
+	var stream io.ReadCloser = resp.Body
+	if client.streamOptions.AutoReconnect {
+		stream = newReconnectingReader(ctx, resp.Body, client.streamOptions, func(reopenCtx context.Context, lastEventID string) (io.ReadCloser, error) {
+			reopenResp, err := client.doChatCompletionsStreamRequest(reopenCtx, body, lastEventID)
+			if err != nil {
+				return nil, err
+			}
+			return reopenResp.Body, nil
+		})
+	}
+
+	return GetChatCompletionsStreamResponse{
+		ChatCompletionsStream: newEventReader[ChatCompletions](stream),
+	}, nil
+}
+
+// doChatCompletionsStreamRequest builds and sends a single streaming GetChatCompletionsStream
+// request, optionally carrying a Last-Event-ID header so the server can resume after lastEventID,
+// if given.
+func (client *Client) doChatCompletionsStreamRequest(ctx context.Context, body ChatCompletionsOptions, lastEventID ...string) (*http.Response, error) {
+	req, err := client.getChatCompletionsCreateRequest(ctx, body, &GetChatCompletionsOptions{})
+	if err != nil {
+		return nil, err
+	}
+
 	if err := runtime.MarshalAsJSON(req, streamCompletionsOptions{
 		any:    body,
 		Stream: true,
 	}); err != nil {
-		return GetChatCompletionsStreamResponse{}, err
+		return nil, err
 	}
 
 	runtime.SkipBodyDownload(req)
 
-	resp, err := client.internal.Pipeline().Do(req)
+	if len(lastEventID) > 0 && lastEventID[0] != "" {
+		req.Raw().Header.Set("Last-Event-ID", lastEventID[0])
+	}
 
+	resp, err := client.internal.Pipeline().Do(req)
 	if err != nil {
-		return GetChatCompletionsStreamResponse{}, err
+		return nil, err
 	}
 
 	if !runtime.HasStatusCode(resp, http.StatusOK) {
 		_ = resp.Body.Close()
-		return GetChatCompletionsStreamResponse{}, runtime.NewResponseError(resp)
+		return nil, runtime.NewResponseError(resp)
 	}
 
-	return GetChatCompletionsStreamResponse{
-		ChatCompletionsStream: newEventReader[ChatCompletions](resp.Body),
-	}, nil
+	return resp, nil
 }
 
 func (client *Client) formatURL(path string, deployment *string) string {
@@ -256,7 +467,7 @@ func (client *Client) formatURL(path string, deployment *string) string {
 	case "/images/generations:submit":
 		return runtime.JoinPaths(client.endpoint, path)
 	default:
-		if client.azure {
+		if client.backend == BackendAzure {
 			if deployment != nil {
 				escapedDeplID := url.PathEscape(*deployment)
 				return runtime.JoinPaths(client.endpoint, "openai", "deployments", escapedDeplID, path)
@@ -274,8 +485,45 @@ func (client *Client) newError(resp *http.Response) error {
 }
 
 type clientData struct {
-	endpoint string
-	azure    bool
+	endpoint            string
+	backend             Backend
+	rateLimitPolicy     *rateLimitRetryPolicy
+	preflightTokenCheck bool
+	streamOptions       StreamOptions
+}
+
+// RateLimitInfo returns the rate-limit state observed on the most recently completed request,
+// parsed from the x-ratelimit-* response headers. The zero value is returned if no request has
+// completed yet.
+func (client *Client) RateLimitInfo() RateLimitInfo {
+	if client.rateLimitPolicy == nil {
+		return RateLimitInfo{}
+	}
+	return client.rateLimitPolicy.LastRateLimitInfo()
+}
+
+// modelHeaderContextKey is the context key used to pass a deployment/model name through to
+// modelHeaderPolicy for backends that expect the model in a header rather than the URL path.
+type modelHeaderContextKey struct{}
+
+// withModelHeaderValue attaches the deployment/model name to ctx so that, if ClientOptions.ModelHeaderName
+// is set, modelHeaderPolicy can surface it as a request header.
+func withModelHeaderValue(ctx context.Context, model string) context.Context {
+	return context.WithValue(ctx, modelHeaderContextKey{}, model)
+}
+
+// modelHeaderPolicy sets a configurable header to the deployment/model name, for OpenAI-compatible
+// backends that expect the model there instead of (or in addition to) the URL path.
+type modelHeaderPolicy struct {
+	headerName string
+}
+
+// Do implements the policy.Policy interface for type modelHeaderPolicy.
+func (p *modelHeaderPolicy) Do(req *policy.Request) (*http.Response, error) {
+	if model, ok := req.Raw().Context().Value(modelHeaderContextKey{}).(string); ok && model != "" {
+		req.Raw().Header.Set(p.headerName, model)
+	}
+	return req.Next()
 }
 
 func getDeployment[T SpeechGenerationOptions | AudioTranscriptionOptions | AudioTranslationOptions | ChatCompletionsOptions | CompletionsOptions | EmbeddingsOptions | *getAudioTranscriptionInternalOptions | *getAudioTranslationInternalOptions | ImageGenerationOptions](v T) *string {
@@ -330,6 +578,24 @@ func (c *ChatRequestUserMessageContent) UnmarshalJSON(data []byte) error {
 	return json.Unmarshal(data, &c.value)
 }
 
+// text returns a plain-text approximation of the content, for callers (such as the pre-flight
+// token check) that need to estimate size rather than reproduce the content exactly: the string
+// itself when it was built from a string, or the JSON encoding of the content parts when it was
+// built from a []ChatCompletionRequestMessageContentPartClassification.
+func (c *ChatRequestUserMessageContent) text() string {
+	switch v := c.value.(type) {
+	case *string:
+		if v != nil {
+			return *v
+		}
+	case []ChatCompletionRequestMessageContentPartClassification:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
 func allowInsecure(options *ClientOptions) bool {
 	return options != nil && options.InsecureAllowCredentialWithHTTP
 }
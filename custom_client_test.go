@@ -0,0 +1,81 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+type stubPolicy struct {
+	do func(req *policy.Request) (*http.Response, error)
+}
+
+func (p *stubPolicy) Do(req *policy.Request) (*http.Response, error) {
+	return p.do(req)
+}
+
+func TestModelHeaderPolicySetsHeaderFromContext(t *testing.T) {
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	var gotHeader string
+	terminal := &stubPolicy{do: func(req *policy.Request) (*http.Response, error) {
+		gotHeader = req.Raw().Header.Get("x-model")
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}}
+
+	pipeline := runtime.NewPipeline("test", "v1", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{&modelHeaderPolicy{headerName: "x-model"}, terminal},
+	}, nil)
+
+	ctx := withModelHeaderValue(context.Background(), "gpt-4o-mini")
+	req, err = runtime.NewRequest(ctx, http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	if _, err := pipeline.Do(req); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if gotHeader != "gpt-4o-mini" {
+		t.Fatalf("header = %q, want %q", gotHeader, "gpt-4o-mini")
+	}
+}
+
+func TestModelHeaderPolicyLeavesHeaderUnsetWithoutContextValue(t *testing.T) {
+	var gotHeader string
+	var sawHeader bool
+	terminal := &stubPolicy{do: func(req *policy.Request) (*http.Response, error) {
+		gotHeader, sawHeader = req.Raw().Header.Get("x-model"), req.Raw().Header.Get("x-model") != ""
+		return &http.Response{StatusCode: http.StatusOK}, nil
+	}}
+
+	pipeline := runtime.NewPipeline("test", "v1", runtime.PipelineOptions{
+		PerRetry: []policy.Policy{&modelHeaderPolicy{headerName: "x-model"}, terminal},
+	}, nil)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	if _, err := pipeline.Do(req); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if sawHeader {
+		t.Fatalf("header unexpectedly set to %q", gotHeader)
+	}
+}
@@ -0,0 +1,225 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+// this file contains handwritten additions to the generated code, adding an optional pre-flight
+// check that rejects requests which would exceed a model's context window before they're sent.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/jokerhaos/azopenai/tokencount"
+)
+
+// TokenLimitError is returned by the pre-flight check installed via ClientOptions.PreflightTokenCheck
+// when a request's estimated token usage would exceed the model's context window.
+type TokenLimitError struct {
+	// Model is the deployment/model name the request was estimated against.
+	Model string
+
+	// Limit is the context window size, in tokens, that was exceeded.
+	Limit int
+
+	// Computed is the estimated prompt tokens plus the request's MaxTokens.
+	Computed int
+}
+
+// Error implements the error interface for type TokenLimitError.
+func (e *TokenLimitError) Error() string {
+	return fmt.Sprintf("azopenai: request for model %q estimated at %d tokens exceeds its %d token limit", e.Model, e.Computed, e.Limit)
+}
+
+// contextWindows holds the known context window size, in tokens, for model families recognized by
+// the pre-flight check. Models not listed here are not checked.
+var contextWindows = map[string]int{
+	"gpt-3.5-turbo": 16385,
+	"gpt-4":         8192,
+	"gpt-4-turbo":   128000,
+	"gpt-4o":        128000,
+	"gpt-4o-mini":   128000,
+}
+
+// checkPreflightTokenLimit estimates the prompt token usage of msgs and tools and returns a
+// *TokenLimitError if it, plus maxTokens, would exceed the model's known context window. It returns
+// nil (performing no check) if model is empty or isn't a recognized family.
+func checkPreflightTokenLimit(model string, msgs []tokencount.ChatMessage, tools []tokencount.ChatTool, maxTokens int) error {
+	limit, ok := contextWindowForModel(model)
+	if !ok {
+		return nil
+	}
+
+	promptTokens, err := tokencount.CountChatTokens(model, msgs, tools)
+	if err != nil {
+		return fmt.Errorf("azopenai: pre-flight token check failed: %w", err)
+	}
+
+	computed := promptTokens + maxTokens
+	if computed > limit {
+		return &TokenLimitError{Model: model, Limit: limit, Computed: computed}
+	}
+
+	return nil
+}
+
+// contextWindowForModel looks up the context window for model, matching the longest registered
+// prefix so that dated model names (e.g. "gpt-4o-2024-08-06") resolve to their family's entry.
+func contextWindowForModel(model string) (int, bool) {
+	var best string
+	for prefix := range contextWindows {
+		if len(model) >= len(prefix) && model[:len(prefix)] == prefix && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+
+	if best == "" {
+		return 0, false
+	}
+
+	return contextWindows[best], true
+}
+
+// GetChatCompletionsWithPreflightCheck calls through to GetChatCompletions, first running the
+// pre-flight token check described on ClientOptions.PreflightTokenCheck if it's enabled.
+func (client *Client) GetChatCompletionsWithPreflightCheck(ctx context.Context, body ChatCompletionsOptions, options *GetChatCompletionsOptions) (GetChatCompletionsResponse, error) {
+	if client.preflightTokenCheck {
+		if err := checkPreflightTokenLimit(modelForPreflight(body), chatMessagesForTokenCount(body.Messages), toolsForTokenCount(body.Tools), maxTokensForPreflight(body)); err != nil {
+			return GetChatCompletionsResponse{}, err
+		}
+	}
+
+	return client.GetChatCompletions(ctx, body, options)
+}
+
+// modelForPreflight returns the model/deployment name used to look up context window limits:
+// body.DeploymentName, or "" when it's unset (which skips the check).
+func modelForPreflight(body ChatCompletionsOptions) string {
+	if body.DeploymentName != nil {
+		return *body.DeploymentName
+	}
+	return ""
+}
+
+// maxTokensForPreflight returns body.MaxTokens, treating an unset value as 0 extra tokens to
+// reserve for the completion.
+func maxTokensForPreflight(body ChatCompletionsOptions) int {
+	if body.MaxTokens != nil {
+		return int(*body.MaxTokens)
+	}
+	return 0
+}
+
+// chatMessagesForTokenCount adapts the package's polymorphic chat message types to the plain
+// tokencount.ChatMessage shape CountChatTokens expects, extracting each message's actual text
+// content rather than its serialized JSON (which would double-count the role and field names).
+func chatMessagesForTokenCount(msgs []ChatRequestMessageClassification) []tokencount.ChatMessage {
+	result := make([]tokencount.ChatMessage, 0, len(msgs))
+
+	for _, m := range msgs {
+		var role string
+		if base := m.GetChatRequestMessage(); base != nil && base.role != nil {
+			role = string(*base.role)
+		}
+
+		content := ""
+		switch tm := m.(type) {
+		case *ChatRequestSystemMessage:
+			if tm.Content != nil {
+				content = tm.Content.text()
+			}
+		case *ChatRequestUserMessage:
+			if tm.Content != nil {
+				content = tm.Content.text()
+			}
+		case *ChatRequestAssistantMessage:
+			if tm.Content != nil {
+				content = tm.Content.text()
+			}
+		case *ChatRequestFunctionMessage:
+			if tm.Content != nil {
+				content = *tm.Content
+			}
+		case *ChatRequestToolMessage:
+			if tm.Content != nil {
+				content = tm.Content.text()
+			}
+		}
+
+		result = append(result, tokencount.ChatMessage{Role: role, Content: content})
+	}
+
+	return result
+}
+
+// text returns a plain-text approximation of the content, for callers (such as the pre-flight
+// token check) that need to estimate size rather than reproduce the content exactly: the string
+// itself when it was built from a string, or the JSON encoding of the content parts when it was
+// built from a slice of content items.
+func (c *ChatRequestSystemMessageContent) text() string {
+	switch v := c.value.(type) {
+	case string:
+		return v
+	case []ChatMessageTextContentItem:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// text returns a plain-text approximation of the content; see [ChatRequestSystemMessageContent.text].
+func (c *ChatRequestAssistantMessageContent) text() string {
+	switch v := c.value.(type) {
+	case string:
+		return v
+	case []ChatMessageTextContentItem, []ChatMessageRefusalContentItem:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// text returns a plain-text approximation of the content; see [ChatRequestSystemMessageContent.text].
+func (c *ChatRequestToolMessageContent) text() string {
+	switch v := c.value.(type) {
+	case string:
+		return v
+	case []ChatMessageTextContentItem:
+		if b, err := json.Marshal(v); err == nil {
+			return string(b)
+		}
+	}
+	return ""
+}
+
+// toolsForTokenCount adapts the package's tool definitions to the plain tokencount.ChatTool shape
+// CountChatTokens expects.
+func toolsForTokenCount(tools []ChatCompletionsToolDefinitionClassification) []tokencount.ChatTool {
+	result := make([]tokencount.ChatTool, 0, len(tools))
+
+	for _, t := range tools {
+		ft, ok := t.(*ChatCompletionsFunctionToolDefinition)
+		if !ok || ft.Function == nil {
+			continue
+		}
+
+		tool := tokencount.ChatTool{}
+		if ft.Function.Name != nil {
+			tool.Name = *ft.Function.Name
+		}
+		if ft.Function.Description != nil {
+			tool.Description = *ft.Function.Description
+		}
+		tool.Parameters = string(ft.Function.Parameters)
+
+		result = append(result, tool)
+	}
+
+	return result
+}
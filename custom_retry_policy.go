@@ -0,0 +1,247 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+// this file contains handwritten additions to the generated code, adding a retry policy that
+// honors the rate-limit headers returned by Azure OpenAI and OpenAI on 429/503 responses.
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+)
+
+// RetryOptions configures rateLimitRetryPolicy, the policy installed by default in NewClient,
+// NewClientWithKeyCredential, NewClientForOpenAI and NewClientForOpenAICompatible that retries
+// HTTP 429 and 503 responses.
+type RetryOptions struct {
+	// MaxRetries is the maximum number of times a request will be retried after a 429 or 503
+	// response. Defaults to 3.
+	MaxRetries int32
+
+	// MaxRetryDelay caps how long the policy will sleep before a single retry, regardless of what
+	// the server's Retry-After or x-ratelimit-reset-* headers requested. Defaults to one minute.
+	MaxRetryDelay time.Duration
+}
+
+const (
+	defaultMaxRetries    = int32(3)
+	defaultMaxRetryDelay = time.Minute
+)
+
+// RateLimitInfo is the rate-limit state most recently observed for a Client, parsed from the
+// x-ratelimit-* response headers. Retrieve it with [azopenai.RateLimitInfoFromResponse].
+type RateLimitInfo struct {
+	// RemainingRequests is the number of requests still permitted in the current window, from the
+	// x-ratelimit-remaining-requests header.
+	RemainingRequests *int
+
+	// RemainingTokens is the number of tokens still permitted in the current window, from the
+	// x-ratelimit-remaining-tokens header.
+	RemainingTokens *int
+
+	// ResetRequests is when the request-count window resets, derived from x-ratelimit-reset-requests.
+	ResetRequests *time.Time
+
+	// ResetTokens is when the token-count window resets, derived from x-ratelimit-reset-tokens.
+	ResetTokens *time.Time
+}
+
+// RateLimitInfoFromResponse parses the x-ratelimit-* headers on resp. It returns a zero-value
+// RateLimitInfo (all fields nil) if resp is nil or carries none of these headers.
+func RateLimitInfoFromResponse(resp *http.Response) RateLimitInfo {
+	var info RateLimitInfo
+	if resp == nil {
+		return info
+	}
+
+	info.RemainingRequests = parseIntHeader(resp.Header, "x-ratelimit-remaining-requests")
+	info.RemainingTokens = parseIntHeader(resp.Header, "x-ratelimit-remaining-tokens")
+	info.ResetRequests = parseRateLimitReset(resp.Header.Get("x-ratelimit-reset-requests"))
+	info.ResetTokens = parseRateLimitReset(resp.Header.Get("x-ratelimit-reset-tokens"))
+
+	return info
+}
+
+func parseIntHeader(h http.Header, name string) *int {
+	v := h.Get(name)
+	if v == "" {
+		return nil
+	}
+
+	n, err := strconv.Atoi(v)
+	if err != nil {
+		return nil
+	}
+
+	return &n
+}
+
+// parseRateLimitReset parses the duration-formatted x-ratelimit-reset-* headers (e.g. "1s", "6m0s")
+// into an absolute time, relative to now.
+func parseRateLimitReset(v string) *time.Time {
+	if v == "" {
+		return nil
+	}
+
+	d, err := time.ParseDuration(v)
+	if err != nil {
+		return nil
+	}
+
+	t := time.Now().Add(d)
+	return &t
+}
+
+// parseRetryAfter parses the standard Retry-After header, which is either a number of seconds or
+// an HTTP-date, into an absolute time relative to now.
+func parseRetryAfter(v string) (time.Time, bool) {
+	if v == "" {
+		return time.Time{}, false
+	}
+
+	if secs, err := strconv.Atoi(v); err == nil {
+		return time.Now().Add(time.Duration(secs) * time.Second), true
+	}
+
+	if t, err := http.ParseTime(v); err == nil {
+		return t, true
+	}
+
+	return time.Time{}, false
+}
+
+// rateLimitRetryPolicy retries 429 and 503 responses, sleeping until the earliest of the
+// Retry-After, x-ratelimit-reset-requests and x-ratelimit-reset-tokens headers (bounded by
+// MaxRetryDelay), up to MaxRetries times.
+type rateLimitRetryPolicy struct {
+	options RetryOptions
+
+	mu   sync.Mutex
+	last RateLimitInfo
+}
+
+// disableBuiltInRetry prevents azcore's own retry policy from compounding with
+// rateLimitRetryPolicy: azcore.NewClient installs its built-in retry policy (which defaults to
+// retrying 429/503 responses up to three times with its own backoff, ignoring
+// x-ratelimit-reset-*) ahead of the PerRetry policies in the pipeline, so without this a sustained
+// rate limit would be retried by both layers independently. It leaves options.Retry alone if the
+// caller already customized it.
+func disableBuiltInRetry(options *ClientOptions) {
+	if options.ClientOptions.Retry.MaxRetries == 0 {
+		options.ClientOptions.Retry.MaxRetries = -1
+	}
+}
+
+// newRateLimitRetryPolicy creates a new instance of rateLimitRetryPolicy.
+func newRateLimitRetryPolicy(options RetryOptions) *rateLimitRetryPolicy {
+	if options.MaxRetries <= 0 {
+		options.MaxRetries = defaultMaxRetries
+	}
+
+	if options.MaxRetryDelay <= 0 {
+		options.MaxRetryDelay = defaultMaxRetryDelay
+	}
+
+	return &rateLimitRetryPolicy{options: options}
+}
+
+// LastRateLimitInfo returns the rate-limit state observed on the most recently completed
+// response, for callers that want to pace requests client-side.
+func (p *rateLimitRetryPolicy) LastRateLimitInfo() RateLimitInfo {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.last
+}
+
+// Do implements the policy.Policy interface for type rateLimitRetryPolicy.
+func (p *rateLimitRetryPolicy) Do(req *policy.Request) (*http.Response, error) {
+	var resp *http.Response
+	var err error
+
+	for attempt := int32(0); ; attempt++ {
+		resp, err = req.Next()
+		if err != nil {
+			return resp, err
+		}
+
+		p.mu.Lock()
+		p.last = RateLimitInfoFromResponse(resp)
+		p.mu.Unlock()
+
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable {
+			return resp, nil
+		}
+
+		if attempt >= p.options.MaxRetries {
+			return resp, nil
+		}
+
+		delay, ok := p.retryDelay(resp)
+		if !ok {
+			return resp, nil
+		}
+
+		if delay > p.options.MaxRetryDelay {
+			delay = p.options.MaxRetryDelay
+		}
+
+		if err := sleepOrDone(req.Raw().Context(), delay); err != nil {
+			return resp, err
+		}
+
+		if err := req.RewindBody(); err != nil {
+			return resp, err
+		}
+	}
+}
+
+// retryDelay computes how long to wait before retrying resp, picking the earliest of
+// Retry-After, x-ratelimit-reset-requests and x-ratelimit-reset-tokens. ok is false if none of
+// these headers were present, meaning the caller should surface the response as-is.
+func (p *rateLimitRetryPolicy) retryDelay(resp *http.Response) (time.Duration, bool) {
+	now := time.Now()
+	earliest := time.Time{}
+
+	if retryAfter, ok := parseRetryAfter(resp.Header.Get("Retry-After")); ok {
+		earliest = retryAfter
+	}
+
+	info := RateLimitInfoFromResponse(resp)
+	for _, reset := range []*time.Time{info.ResetRequests, info.ResetTokens} {
+		if reset == nil {
+			continue
+		}
+		if earliest.IsZero() || reset.Before(earliest) {
+			earliest = *reset
+		}
+	}
+
+	if earliest.IsZero() {
+		return 0, false
+	}
+
+	return time.Duration(math.Max(0, float64(earliest.Sub(now)))), true
+}
+
+// sleepOrDone sleeps for d, returning ctx.Err() early if ctx is cancelled first.
+func sleepOrDone(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,278 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/policy"
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore/runtime"
+)
+
+// newRetryTestPipeline builds a pipeline running only the given PerRetry policies, with azcore's
+// own built-in retry disabled so these tests observe rateLimitRetryPolicy in isolation, matching
+// how NewClient configures it via disableBuiltInRetry.
+func newRetryTestPipeline(perRetry ...policy.Policy) runtime.Pipeline {
+	return runtime.NewPipeline("test", "v1", runtime.PipelineOptions{
+		PerRetry: perRetry,
+	}, &policy.ClientOptions{Retry: policy.RetryOptions{MaxRetries: -1}})
+}
+
+func TestRateLimitRetryPolicyRetriesUntilSuccess(t *testing.T) {
+	p := newRateLimitRetryPolicy(RetryOptions{MaxRetries: 3})
+
+	calls := 0
+	terminal := &stubPolicy{do: func(req *policy.Request) (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"0"}},
+				Body:       http.NoBody,
+			}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}}
+
+	pipeline := newRetryTestPipeline(p, terminal)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	resp, err := pipeline.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRateLimitRetryPolicyStopsAtMaxRetries(t *testing.T) {
+	p := newRateLimitRetryPolicy(RetryOptions{MaxRetries: 2})
+
+	calls := 0
+	terminal := &stubPolicy{do: func(req *policy.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{
+			StatusCode: http.StatusServiceUnavailable,
+			Header:     http.Header{"Retry-After": []string{"0"}},
+			Body:       http.NoBody,
+		}, nil
+	}}
+
+	pipeline := newRetryTestPipeline(p, terminal)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	resp, err := pipeline.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusServiceUnavailable)
+	}
+	// the initial try plus MaxRetries retries
+	if calls != 3 {
+		t.Fatalf("calls = %d, want 3", calls)
+	}
+}
+
+func TestRateLimitRetryPolicyLeavesNonRateLimitResponsesAlone(t *testing.T) {
+	p := newRateLimitRetryPolicy(RetryOptions{MaxRetries: 3})
+
+	calls := 0
+	terminal := &stubPolicy{do: func(req *policy.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusBadRequest, Body: http.NoBody}, nil
+	}}
+
+	pipeline := newRetryTestPipeline(p, terminal)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	if _, err := pipeline.Do(req); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRateLimitRetryPolicyStopsWithoutRetryHeaders(t *testing.T) {
+	p := newRateLimitRetryPolicy(RetryOptions{MaxRetries: 3})
+
+	calls := 0
+	terminal := &stubPolicy{do: func(req *policy.Request) (*http.Response, error) {
+		calls++
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Body: http.NoBody}, nil
+	}}
+
+	pipeline := newRetryTestPipeline(p, terminal)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	resp, err := pipeline.Do(req)
+	if err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+	if resp.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("StatusCode = %d, want %d", resp.StatusCode, http.StatusTooManyRequests)
+	}
+	// no Retry-After or x-ratelimit-reset-* header: nothing to wait for, so no retry.
+	if calls != 1 {
+		t.Fatalf("calls = %d, want 1", calls)
+	}
+}
+
+func TestRateLimitRetryPolicyPrefersEarliestResetHeader(t *testing.T) {
+	p := newRateLimitRetryPolicy(RetryOptions{MaxRetries: 1})
+
+	resp := &http.Response{
+		StatusCode: http.StatusTooManyRequests,
+		Header: http.Header{
+			"Retry-After":              []string{"30"},
+			"X-Ratelimit-Reset-Tokens": []string{"0s"},
+		},
+	}
+
+	delay, ok := p.retryDelay(resp)
+	if !ok {
+		t.Fatalf("retryDelay: ok = false, want true")
+	}
+	if delay >= 30*time.Second {
+		t.Fatalf("delay = %v, want less than the 30s Retry-After (x-ratelimit-reset-tokens is earlier)", delay)
+	}
+}
+
+func TestRateLimitRetryPolicyCapsDelayAtMaxRetryDelay(t *testing.T) {
+	p := newRateLimitRetryPolicy(RetryOptions{MaxRetries: 1, MaxRetryDelay: time.Second})
+
+	calls := 0
+	start := time.Now()
+	terminal := &stubPolicy{do: func(req *policy.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return &http.Response{
+				StatusCode: http.StatusTooManyRequests,
+				Header:     http.Header{"Retry-After": []string{"3600"}},
+				Body:       http.NoBody,
+			}, nil
+		}
+		return &http.Response{StatusCode: http.StatusOK, Body: http.NoBody}, nil
+	}}
+
+	pipeline := newRetryTestPipeline(p, terminal)
+
+	req, err := runtime.NewRequest(context.Background(), http.MethodPost, "https://example.com")
+	if err != nil {
+		t.Fatalf("NewRequest: %v", err)
+	}
+
+	if _, err := pipeline.Do(req); err != nil {
+		t.Fatalf("Do: %v", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Fatalf("elapsed = %v, want capped around MaxRetryDelay (1s)", elapsed)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	tests := []struct {
+		name string
+		v    string
+		ok   bool
+	}{
+		{"empty", "", false},
+		{"seconds", "5", true},
+		{"http-date", time.Now().Add(time.Minute).UTC().Format(http.TimeFormat), true},
+		{"garbage", "not-a-date", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, ok := parseRetryAfter(tt.v)
+			if ok != tt.ok {
+				t.Fatalf("ok = %v, want %v", ok, tt.ok)
+			}
+		})
+	}
+}
+
+func TestRateLimitInfoFromResponse(t *testing.T) {
+	resp := &http.Response{
+		Header: http.Header{
+			"X-Ratelimit-Remaining-Requests": []string{"10"},
+			"X-Ratelimit-Remaining-Tokens":   []string{"1000"},
+			"X-Ratelimit-Reset-Requests":     []string{"1s"},
+			"X-Ratelimit-Reset-Tokens":       []string{"6m0s"},
+		},
+	}
+
+	info := RateLimitInfoFromResponse(resp)
+
+	if info.RemainingRequests == nil || *info.RemainingRequests != 10 {
+		t.Fatalf("RemainingRequests = %v, want 10", info.RemainingRequests)
+	}
+	if info.RemainingTokens == nil || *info.RemainingTokens != 1000 {
+		t.Fatalf("RemainingTokens = %v, want 1000", info.RemainingTokens)
+	}
+	if info.ResetRequests == nil {
+		t.Fatalf("ResetRequests = nil")
+	}
+	if info.ResetTokens == nil {
+		t.Fatalf("ResetTokens = nil")
+	}
+
+	if RateLimitInfoFromResponse(nil) != (RateLimitInfo{}) {
+		t.Fatalf("RateLimitInfoFromResponse(nil) is not the zero value")
+	}
+}
+
+func TestDisableBuiltInRetryDefaultsToDisabled(t *testing.T) {
+	options := &ClientOptions{}
+	disableBuiltInRetry(options)
+
+	if options.ClientOptions.Retry.MaxRetries != -1 {
+		t.Fatalf("MaxRetries = %d, want -1", options.ClientOptions.Retry.MaxRetries)
+	}
+}
+
+func TestDisableBuiltInRetryLeavesExplicitValueAlone(t *testing.T) {
+	options := &ClientOptions{
+		ClientOptions: azcore.ClientOptions{
+			Retry: policy.RetryOptions{MaxRetries: 5},
+		},
+	}
+	disableBuiltInRetry(options)
+
+	if options.ClientOptions.Retry.MaxRetries != 5 {
+		t.Fatalf("MaxRetries = %d, want 5 (caller-set value should be left alone)", options.ClientOptions.Retry.MaxRetries)
+	}
+}
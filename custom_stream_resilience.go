@@ -0,0 +1,335 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+// this file contains handwritten additions to the generated code, adding automatic reconnection
+// and delta-accumulation helpers for the SSE streams returned by GetChatCompletionsStream and
+// GetCompletionsStream.
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"math"
+	"strings"
+	"time"
+)
+
+// StreamOptions configures the resilience behavior of a streamed request, in addition to the
+// per-operation GetChatCompletionsStreamOptions/GetCompletionsStreamOptions.
+type StreamOptions struct {
+	// AutoReconnect, when true, makes the stream automatically reconnect - using the SSE
+	// Last-Event-ID header and an exponential backoff - if the underlying HTTP body errors before
+	// a [DONE] sentinel is seen. Reconnection is only attempted if the server assigns event IDs;
+	// servers that don't are read exactly as before.
+	AutoReconnect bool
+
+	// MaxReconnects caps how many times a single stream will reconnect. Defaults to 5.
+	MaxReconnects int
+
+	// ReconnectBaseDelay is the delay before the first reconnect attempt, doubling on each
+	// subsequent attempt. Defaults to 500ms.
+	ReconnectBaseDelay time.Duration
+}
+
+const (
+	defaultMaxReconnects      = 5
+	defaultReconnectBaseDelay = 500 * time.Millisecond
+)
+
+// reconnectingReader wraps an io.ReadCloser, transparently reconnecting (by calling reconnect)
+// when a Read fails before the stream's terminal [DONE] event, up to MaxReconnects times.
+//
+// Bytes are passed through to the caller unmodified (so the generic SSE event reader wrapping
+// this type still sees a normal stream); reconnectingReader separately sniffs each complete line
+// it observes for the SSE "id:" and "data: [DONE]" markers, via noteEventID/noteDone, so it knows
+// where to resume from and when reconnection is no longer appropriate.
+type reconnectingReader struct {
+	ctx      context.Context
+	current  io.ReadCloser
+	reopen   func(ctx context.Context, lastEventID string) (io.ReadCloser, error)
+	options  StreamOptions
+	attempts int
+
+	lastEventID string
+	done        bool
+	pending     []byte
+}
+
+// newReconnectingReader creates a new instance of reconnectingReader.
+func newReconnectingReader(ctx context.Context, initial io.ReadCloser, options StreamOptions, reopen func(ctx context.Context, lastEventID string) (io.ReadCloser, error)) *reconnectingReader {
+	if options.MaxReconnects <= 0 {
+		options.MaxReconnects = defaultMaxReconnects
+	}
+
+	if options.ReconnectBaseDelay <= 0 {
+		options.ReconnectBaseDelay = defaultReconnectBaseDelay
+	}
+
+	return &reconnectingReader{ctx: ctx, current: initial, options: options, reopen: reopen}
+}
+
+// noteEventID records the id of the most recently read SSE event, so a subsequent reconnect can
+// resume via the Last-Event-ID header.
+func (r *reconnectingReader) noteEventID(id string) {
+	if id != "" {
+		r.lastEventID = id
+	}
+}
+
+// noteDone marks the stream as having seen its terminal [DONE] event, after which no further
+// reconnection is attempted.
+func (r *reconnectingReader) noteDone() {
+	r.done = true
+}
+
+// Read implements the io.Reader interface for type reconnectingReader, reconnecting on transport
+// errors per StreamOptions.AutoReconnect.
+func (r *reconnectingReader) Read(p []byte) (int, error) {
+	n, err := r.current.Read(p)
+	if n > 0 {
+		r.sniffLines(p[:n])
+	}
+
+	if err == nil || errors.Is(err, io.EOF) && r.done {
+		return n, err
+	}
+
+	if !r.options.AutoReconnect || r.done || r.lastEventID == "" || r.attempts >= r.options.MaxReconnects {
+		return n, err
+	}
+
+	if reconnectErr := r.reconnect(); reconnectErr != nil {
+		return n, err
+	}
+
+	return n, nil
+}
+
+// sniffLines scans newly read bytes for complete SSE lines, feeding "id:" and "data: [DONE]"
+// lines into noteEventID/noteDone as a side effect. It does not alter the bytes the caller sees.
+func (r *reconnectingReader) sniffLines(b []byte) {
+	r.pending = append(r.pending, b...)
+
+	for {
+		idx := bytes.IndexByte(r.pending, '\n')
+		if idx == -1 {
+			break
+		}
+
+		line := strings.TrimRight(string(r.pending[:idx]), "\r")
+		r.pending = r.pending[idx+1:]
+
+		switch {
+		case strings.HasPrefix(line, "id:"):
+			r.noteEventID(strings.TrimSpace(strings.TrimPrefix(line, "id:")))
+		case strings.HasPrefix(line, "data:") && strings.TrimSpace(strings.TrimPrefix(line, "data:")) == "[DONE]":
+			r.noteDone()
+		}
+	}
+}
+
+// reconnect closes the current body and replaces it with a freshly opened one, resuming from
+// lastEventID, after sleeping for an exponentially increasing backoff.
+func (r *reconnectingReader) reconnect() error {
+	r.attempts++
+
+	delay := time.Duration(float64(r.options.ReconnectBaseDelay) * math.Pow(2, float64(r.attempts-1)))
+	if err := sleepOrDone(r.ctx, delay); err != nil {
+		return err
+	}
+
+	_ = r.current.Close()
+
+	next, err := r.reopen(r.ctx, r.lastEventID)
+	if err != nil {
+		return err
+	}
+
+	r.current = next
+	return nil
+}
+
+// Close implements the io.Closer interface for type reconnectingReader.
+func (r *reconnectingReader) Close() error {
+	return r.current.Close()
+}
+
+// streamAccumulator merges the delta chunks of a streamed chat completion into a final
+// ChatCompletions value, used by the Accumulate method on GetChatCompletionsStreamResponse.
+type streamAccumulator struct {
+	id           string
+	created      *time.Time
+	model        *string
+	systemFP     *string
+	usage        *CompletionsUsage
+	content      map[int]string
+	role         map[int]*ChatRole
+	finishReason map[int]*CompletionsFinishReason
+	toolCalls    map[int]*ToolCallDeltaAccumulator
+	order        []int
+}
+
+// newStreamAccumulator creates a new instance of streamAccumulator.
+func newStreamAccumulator() *streamAccumulator {
+	return &streamAccumulator{
+		content:      map[int]string{},
+		role:         map[int]*ChatRole{},
+		finishReason: map[int]*CompletionsFinishReason{},
+		toolCalls:    map[int]*ToolCallDeltaAccumulator{},
+	}
+}
+
+// Add merges a single streamed ChatCompletions chunk into the accumulator.
+func (a *streamAccumulator) Add(chunk ChatCompletions) {
+	if chunk.ID != nil {
+		a.id = *chunk.ID
+	}
+
+	if chunk.Created != nil {
+		a.created = chunk.Created
+	}
+
+	if chunk.Model != nil {
+		a.model = chunk.Model
+	}
+
+	if chunk.SystemFingerprint != nil {
+		a.systemFP = chunk.SystemFingerprint
+	}
+
+	if chunk.Usage != nil {
+		a.usage = chunk.Usage
+	}
+
+	for _, choice := range chunk.Choices {
+		if choice.Index == nil {
+			continue
+		}
+
+		idx := int(*choice.Index)
+		if _, seen := a.content[idx]; !seen {
+			a.order = append(a.order, idx)
+			a.content[idx] = ""
+		}
+
+		if choice.FinishReason != nil {
+			a.finishReason[idx] = choice.FinishReason
+		}
+
+		if choice.Delta == nil {
+			continue
+		}
+
+		if choice.Delta.Role != nil {
+			a.role[idx] = choice.Delta.Role
+		}
+
+		if choice.Delta.Content != nil {
+			a.content[idx] += *choice.Delta.Content
+		}
+
+		if len(choice.Delta.ToolCalls) > 0 {
+			toolAcc, ok := a.toolCalls[idx]
+			if !ok {
+				toolAcc = &ToolCallDeltaAccumulator{}
+				a.toolCalls[idx] = toolAcc
+			}
+			toolAcc.Add(choice.Delta.ToolCalls)
+		}
+	}
+}
+
+// Result returns the accumulated ChatCompletions, after the last event of the stream has been read.
+func (a *streamAccumulator) Result() ChatCompletions {
+	choices := make([]ChatChoice, 0, len(a.order))
+
+	for _, idx := range a.order {
+		index := int32(idx)
+		content := a.content[idx]
+
+		choice := ChatChoice{
+			Index:        &index,
+			FinishReason: a.finishReason[idx],
+			Message: &ChatResponseMessage{
+				Role:    a.role[idx],
+				Content: &content,
+			},
+		}
+
+		if acc, ok := a.toolCalls[idx]; ok {
+			choice.Message.ToolCalls = acc.ToolCalls()
+		}
+
+		choices = append(choices, choice)
+	}
+
+	result := ChatCompletions{Choices: choices, Model: a.model, SystemFingerprint: a.systemFP, Usage: a.usage, Created: a.created}
+	if a.id != "" {
+		result.ID = &a.id
+	}
+
+	return result
+}
+
+// Accumulate reads every remaining event from the stream and merges it into a final
+// ChatCompletions, returned once the stream's terminal [DONE] event (or EOF) is reached.
+func (resp GetChatCompletionsStreamResponse) Accumulate() (ChatCompletions, error) {
+	acc := newStreamAccumulator()
+
+	for {
+		chunk, err := resp.ChatCompletionsStream.Read()
+		if errors.Is(err, io.EOF) {
+			return acc.Result(), nil
+		}
+		if err != nil {
+			return acc.Result(), err
+		}
+
+		acc.Add(chunk)
+	}
+}
+
+// Chan adapts the stream to a channel of (ChatCompletions, error) pairs, closed after the final
+// event or error, so callers can select on it alongside context cancellation without managing
+// their own reader goroutine.
+func (resp GetChatCompletionsStreamResponse) Chan(ctx context.Context) <-chan streamResult[ChatCompletions] {
+	ch := make(chan streamResult[ChatCompletions])
+
+	go func() {
+		defer close(ch)
+
+		for {
+			chunk, err := resp.ChatCompletionsStream.Read()
+			if err != nil {
+				if !errors.Is(err, io.EOF) {
+					select {
+					case ch <- streamResult[ChatCompletions]{Err: err}:
+					case <-ctx.Done():
+					}
+				}
+				return
+			}
+
+			select {
+			case ch <- streamResult[ChatCompletions]{Value: chunk}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return ch
+}
+
+// streamResult is a single (value, error) pair delivered over the channel returned by
+// GetChatCompletionsStreamResponse.Chan.
+type streamResult[T any] struct {
+	Value T
+	Err   error
+}
@@ -0,0 +1,126 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"testing"
+)
+
+func TestStreamAccumulatorAddMergesContentAndToolCalls(t *testing.T) {
+	idx := int32(0)
+	role := ChatRoleAssistant
+	name := "get_weather"
+	id := "call_1"
+
+	acc := newStreamAccumulator()
+
+	acc.Add(ChatCompletions{
+		Choices: []ChatChoice{{
+			Index: &idx,
+			Delta: &ChatResponseMessage{
+				Role: &role,
+				ToolCalls: []ChatCompletionsToolCallClassification{
+					&ChatCompletionsFunctionToolCall{
+						ID:       &id,
+						Function: &FunctionCall{Name: &name, Arguments: strPtr(`{"loc`)},
+					},
+				},
+			},
+		}},
+	})
+
+	acc.Add(ChatCompletions{
+		Choices: []ChatChoice{{
+			Index: &idx,
+			Delta: &ChatResponseMessage{
+				Content: strPtr("hello"),
+				ToolCalls: []ChatCompletionsToolCallClassification{
+					&ChatCompletionsFunctionToolCall{
+						Function: &FunctionCall{Arguments: strPtr(`ation":"NYC"}`)},
+					},
+				},
+			},
+		}},
+	})
+
+	result := acc.Result()
+	if len(result.Choices) != 1 {
+		t.Fatalf("got %d choices, want 1", len(result.Choices))
+	}
+
+	choice := result.Choices[0]
+	if got, want := *choice.Message.Content, "hello"; got != want {
+		t.Fatalf("content = %q, want %q", got, want)
+	}
+	if got, want := *choice.Message.Role, role; got != want {
+		t.Fatalf("role = %q, want %q", got, want)
+	}
+
+	if len(choice.Message.ToolCalls) != 1 {
+		t.Fatalf("got %d tool calls, want 1", len(choice.Message.ToolCalls))
+	}
+	call, ok := choice.Message.ToolCalls[0].(*ChatCompletionsFunctionToolCall)
+	if !ok {
+		t.Fatalf("ToolCalls[0] is %T, want *ChatCompletionsFunctionToolCall", choice.Message.ToolCalls[0])
+	}
+	if got, want := *call.Function.Arguments, `{"location":"NYC"}`; got != want {
+		t.Fatalf("accumulated arguments = %q, want %q", got, want)
+	}
+}
+
+// flakyReadCloser returns a "connection reset" error in place of its first io.EOF, simulating a
+// transport failure after its data has been read; the test's reopen then supplies a fresh body.
+type flakyReadCloser struct {
+	r      *bytes.Reader
+	failed bool
+}
+
+func (f *flakyReadCloser) Read(p []byte) (int, error) {
+	n, err := f.r.Read(p)
+	if err == io.EOF && !f.failed {
+		f.failed = true
+		return n, errors.New("connection reset")
+	}
+	return n, err
+}
+
+func (f *flakyReadCloser) Close() error { return nil }
+
+func TestReconnectingReaderReconnectsOnError(t *testing.T) {
+	first := &flakyReadCloser{r: bytes.NewReader([]byte("id: 1\ndata: hello\n\n"))}
+	second := bytes.NewReader([]byte("data: [DONE]\n\n"))
+
+	reopenCalls := 0
+	reader := newReconnectingReader(context.Background(), first, StreamOptions{
+		AutoReconnect:      true,
+		ReconnectBaseDelay: 0,
+	}, func(ctx context.Context, lastEventID string) (io.ReadCloser, error) {
+		reopenCalls++
+		if lastEventID != "1" {
+			t.Fatalf("reopen called with lastEventID = %q, want %q", lastEventID, "1")
+		}
+		return io.NopCloser(second), nil
+	})
+
+	buf, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("ReadAll: %v", err)
+	}
+
+	if reopenCalls != 1 {
+		t.Fatalf("reopen called %d times, want 1", reopenCalls)
+	}
+
+	want := "id: 1\ndata: hello\n\ndata: [DONE]\n\n"
+	if string(buf) != want {
+		t.Fatalf("got %q, want %q", buf, want)
+	}
+}
@@ -0,0 +1,207 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+// this file contains handwritten additions to the generated code, adding a reflection-based
+// helper that builds a strict, schema-constrained response_format (ChatCompletionsJSONSchemaResponseFormat)
+// from a Go struct type, plus helpers for unmarshaling the resulting structured output. Plain JSON
+// mode and text mode are already covered by the generated ChatCompletionsJSONResponseFormat and
+// ChatCompletionsTextResponseFormat.
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// NewChatResponseFormatJSONSchema reflects T into a JSON schema suitable for use as a strict,
+// structured output and returns the corresponding [azopenai.ChatCompletionsJSONSchemaResponseFormat],
+// assignable to ChatCompletionsOptions.ResponseFormat. T must be a struct type.
+//
+// Field names are derived from the "json" struct tag (falling back to the field name), fields are
+// all considered required unless tagged `json:",omitempty"`, and the generated schema always sets
+// additionalProperties to false. An `enum:"a,b,c"` struct tag adds an enum constraint to that field.
+func NewChatResponseFormatJSONSchema[T any](name string) (*ChatCompletionsJSONSchemaResponseFormat, error) {
+	var zero T
+
+	schema, err := reflectJSONSchema(reflect.TypeOf(zero))
+	if err != nil {
+		return nil, fmt.Errorf("azopenai: failed to build JSON schema for %T: %w", zero, err)
+	}
+
+	schemaJSON, err := json.Marshal(schema)
+	if err != nil {
+		return nil, fmt.Errorf("azopenai: failed to marshal JSON schema for %T: %w", zero, err)
+	}
+
+	strict := true
+
+	return &ChatCompletionsJSONSchemaResponseFormat{
+		JSONSchema: &ChatCompletionsJSONSchemaResponseFormatJSONSchema{
+			Name:   &name,
+			Schema: schemaJSON,
+			Strict: &strict,
+		},
+	}, nil
+}
+
+// reflectJSONSchema builds a JSON Schema "object" node describing the exported fields of t, which
+// must be a struct type.
+func reflectJSONSchema(t reflect.Type) (map[string]any, error) {
+	if t == nil {
+		return nil, fmt.Errorf("azopenai: type parameter has no concrete type (was it instantiated with an interface type?)")
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("azopenai: type %s is not a struct", t)
+	}
+
+	properties := map[string]any{}
+	var required []string
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		jsonTag := field.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+
+		parts := strings.Split(jsonTag, ",")
+		name := parts[0]
+		if name == "" {
+			name = field.Name
+		}
+
+		omitempty := false
+		for _, p := range parts[1:] {
+			if p == "omitempty" {
+				omitempty = true
+			}
+		}
+
+		fieldSchema, err := reflectFieldSchema(field.Type)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", field.Name, err)
+		}
+
+		if enumTag := field.Tag.Get("enum"); enumTag != "" {
+			values := strings.Split(enumTag, ",")
+			enum := make([]any, len(values))
+			for i, v := range values {
+				ev, err := convertEnumValue(v, field.Type)
+				if err != nil {
+					return nil, fmt.Errorf("field %s: enum value %q: %w", field.Name, v, err)
+				}
+				enum[i] = ev
+			}
+			fieldSchema["enum"] = enum
+		}
+
+		properties[name] = fieldSchema
+		if !omitempty {
+			required = append(required, name)
+		}
+	}
+
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}, nil
+}
+
+// convertEnumValue parses a single comma-separated `enum` tag value into the Go type matching t
+// (after unwrapping pointers), so the generated "enum" array matches the type declared by
+// reflectFieldSchema instead of always being emitted as a string.
+func convertEnumValue(raw string, t reflect.Type) (any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return strconv.ParseBool(strings.TrimSpace(raw))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.ParseInt(strings.TrimSpace(raw), 10, 64)
+	case reflect.Float32, reflect.Float64:
+		return strconv.ParseFloat(strings.TrimSpace(raw), 64)
+	default:
+		return raw, nil
+	}
+}
+
+// reflectFieldSchema maps a single Go field type to its JSON Schema representation.
+func reflectFieldSchema(t reflect.Type) (map[string]any, error) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}, nil
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}, nil
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}, nil
+	case reflect.Slice, reflect.Array:
+		items, err := reflectFieldSchema(t.Elem())
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"type": "array", "items": items}, nil
+	case reflect.Struct:
+		return reflectJSONSchema(t)
+	default:
+		return nil, fmt.Errorf("unsupported kind %s", t.Kind())
+	}
+}
+
+// UnmarshalResponseInto unmarshals the content of the first choice's assistant message in resp
+// into a new *T, for use with a response_format produced by [azopenai.NewChatResponseFormatJSONSchema].
+// It returns an error if resp has no choices or the assistant message has no content.
+func UnmarshalResponseInto[T any](resp ChatCompletions) (*T, error) {
+	if len(resp.Choices) == 0 || resp.Choices[0].Message == nil || resp.Choices[0].Message.Content == nil {
+		return nil, fmt.Errorf("azopenai: response contains no message content to unmarshal")
+	}
+
+	var v T
+	if err := json.Unmarshal([]byte(*resp.Choices[0].Message.Content), &v); err != nil {
+		return nil, fmt.Errorf("azopenai: failed to unmarshal structured output: %w", err)
+	}
+
+	return &v, nil
+}
+
+// GetChatCompletionsTyped calls GetChatCompletions and additionally unmarshals the assistant
+// message content into a *T, for use with a body.ResponseFormat produced by
+// [azopenai.NewChatResponseFormatJSONSchema]. The raw GetChatCompletionsResponse is always
+// returned alongside the typed value so callers can still inspect usage, finish reason, etc.
+func GetChatCompletionsTyped[T any](ctx context.Context, client *Client, body ChatCompletionsOptions, options *GetChatCompletionsOptions) (*T, GetChatCompletionsResponse, error) {
+	resp, err := client.GetChatCompletions(ctx, body, options)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	v, err := UnmarshalResponseInto[T](resp.ChatCompletions)
+	if err != nil {
+		return nil, resp, err
+	}
+
+	return v, resp, nil
+}
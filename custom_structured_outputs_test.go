@@ -0,0 +1,160 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+type weatherQuery struct {
+	Location string  `json:"location"`
+	Unit     string  `json:"unit,omitempty" enum:"celsius,fahrenheit"`
+	Priority int     `json:"priority" enum:"1,2,3"`
+	Verified bool    `json:"verified,omitempty" enum:"true,false"`
+	Temp     float64 `json:"temp,omitempty"`
+}
+
+func TestNewChatResponseFormatJSONSchema(t *testing.T) {
+	format, err := NewChatResponseFormatJSONSchema[weatherQuery]("weather_query")
+	if err != nil {
+		t.Fatalf("NewChatResponseFormatJSONSchema: %v", err)
+	}
+
+	if format.JSONSchema == nil || format.JSONSchema.Name == nil || *format.JSONSchema.Name != "weather_query" {
+		t.Fatalf("JSONSchema.Name = %v, want weather_query", format.JSONSchema)
+	}
+	if format.JSONSchema.Strict == nil || !*format.JSONSchema.Strict {
+		t.Fatalf("Strict = %v, want true", format.JSONSchema.Strict)
+	}
+
+	var schema map[string]any
+	if err := json.Unmarshal(format.JSONSchema.Schema, &schema); err != nil {
+		t.Fatalf("Schema is not valid JSON: %v", err)
+	}
+
+	if schema["type"] != "object" {
+		t.Fatalf("type = %v, want object", schema["type"])
+	}
+	if schema["additionalProperties"] != false {
+		t.Fatalf("additionalProperties = %v, want false", schema["additionalProperties"])
+	}
+
+	required, ok := schema["required"].([]any)
+	if !ok {
+		t.Fatalf("required is %T, want []any", schema["required"])
+	}
+	wantRequired := map[string]bool{"location": true, "priority": true}
+	if len(required) != len(wantRequired) {
+		t.Fatalf("required = %v, want %v", required, wantRequired)
+	}
+	for _, name := range required {
+		if !wantRequired[name.(string)] {
+			t.Fatalf("required contains unexpected field %q", name)
+		}
+	}
+
+	properties, ok := schema["properties"].(map[string]any)
+	if !ok {
+		t.Fatalf("properties is %T, want map[string]any", schema["properties"])
+	}
+
+	unit, ok := properties["unit"].(map[string]any)
+	if !ok {
+		t.Fatalf("properties[unit] is %T, want map[string]any", properties["unit"])
+	}
+	if unit["type"] != "string" {
+		t.Fatalf("unit.type = %v, want string", unit["type"])
+	}
+	if diff := unit["enum"]; !reflect.DeepEqual(diff, []any{"celsius", "fahrenheit"}) {
+		t.Fatalf("unit.enum = %v, want [celsius fahrenheit]", diff)
+	}
+
+	priority, ok := properties["priority"].(map[string]any)
+	if !ok {
+		t.Fatalf("properties[priority] is %T, want map[string]any", properties["priority"])
+	}
+	if priority["type"] != "integer" {
+		t.Fatalf("priority.type = %v, want integer", priority["type"])
+	}
+	if diff := priority["enum"]; !reflect.DeepEqual(diff, []any{float64(1), float64(2), float64(3)}) {
+		t.Fatalf("priority.enum = %v, want [1 2 3] as numbers, not strings", diff)
+	}
+
+	verified, ok := properties["verified"].(map[string]any)
+	if !ok {
+		t.Fatalf("properties[verified] is %T, want map[string]any", properties["verified"])
+	}
+	if diff := verified["enum"]; !reflect.DeepEqual(diff, []any{true, false}) {
+		t.Fatalf("verified.enum = %v, want [true false] as bools, not strings", diff)
+	}
+}
+
+func TestNewChatResponseFormatJSONSchemaNilInterfaceTypeReturnsError(t *testing.T) {
+	_, err := NewChatResponseFormatJSONSchema[any]("anything")
+	if err == nil {
+		t.Fatalf("err = nil, want an error for an interface type with no concrete type")
+	}
+}
+
+func TestNewChatResponseFormatJSONSchemaRejectsNonStruct(t *testing.T) {
+	_, err := NewChatResponseFormatJSONSchema[string]("not_a_struct")
+	if err == nil {
+		t.Fatalf("err = nil, want an error for a non-struct type")
+	}
+}
+
+func TestConvertEnumValue(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  string
+		typ  reflect.Type
+		want any
+	}{
+		{"string", "celsius", reflect.TypeOf(""), "celsius"},
+		{"int", "3", reflect.TypeOf(0), int64(3)},
+		{"bool", "true", reflect.TypeOf(false), true},
+		{"float", "1.5", reflect.TypeOf(0.0), 1.5},
+		{"pointer", "2", reflect.TypeOf(new(int)), int64(2)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := convertEnumValue(tt.raw, tt.typ)
+			if err != nil {
+				t.Fatalf("convertEnumValue: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("got %v (%T), want %v (%T)", got, got, tt.want, tt.want)
+			}
+		})
+	}
+}
+
+func TestUnmarshalResponseInto(t *testing.T) {
+	content := `{"location":"Seattle","priority":1}`
+	resp := ChatCompletions{
+		Choices: []ChatChoice{
+			{Message: &ChatResponseMessage{Content: &content}},
+		},
+	}
+
+	v, err := UnmarshalResponseInto[weatherQuery](resp)
+	if err != nil {
+		t.Fatalf("UnmarshalResponseInto: %v", err)
+	}
+	if v.Location != "Seattle" || v.Priority != 1 {
+		t.Fatalf("v = %+v, want Location=Seattle Priority=1", v)
+	}
+}
+
+func TestUnmarshalResponseIntoNoChoices(t *testing.T) {
+	if _, err := UnmarshalResponseInto[weatherQuery](ChatCompletions{}); err == nil {
+		t.Fatalf("err = nil, want an error for a response with no choices")
+	}
+}
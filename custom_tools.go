@@ -0,0 +1,74 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+// this file contains handwritten additions to the generated code, adding support for
+// accumulating the tool_calls deltas of a streamed chat completion into complete tool calls.
+//
+// Tool/function definitions (ChatCompletionsFunctionToolDefinition), tool messages
+// (ChatRequestToolMessage) and tool choice (ChatCompletionsToolChoice,
+// NewChatCompletionsToolChoice) are already provided by the generated and hand-written models;
+// there's nothing left to add for the non-streaming case.
+
+// ToolCallDeltaAccumulator merges the tool_calls deltas streamed across a sequence of chat
+// completions chunks into complete [azopenai.ChatCompletionsFunctionToolCall] values.
+//
+// The generated ChatCompletionsFunctionToolCall type doesn't carry the streamed tool_calls[].index
+// field - its UnmarshalJSON only populates ID, Type and Function, so deltas can't be merged by
+// index. Instead, a delta that carries a non-nil ID is treated as the start of a new tool call,
+// and one without an ID is treated as a continuation of the most recently started call. This
+// matches how tool call deltas are actually streamed: the ID (and name) are only ever sent on the
+// first chunk of a given call, with subsequent chunks carrying only argument fragments.
+//
+// A zero-value ToolCallDeltaAccumulator is ready to use.
+type ToolCallDeltaAccumulator struct {
+	calls []*ChatCompletionsFunctionToolCall
+}
+
+// Add merges the tool_calls deltas from a single streamed chunk into the accumulator.
+func (a *ToolCallDeltaAccumulator) Add(deltas []ChatCompletionsToolCallClassification) {
+	for _, d := range deltas {
+		delta, ok := d.(*ChatCompletionsFunctionToolCall)
+		if !ok {
+			continue
+		}
+
+		var current *ChatCompletionsFunctionToolCall
+		if delta.ID != nil || len(a.calls) == 0 {
+			current = &ChatCompletionsFunctionToolCall{ID: delta.ID, Type: delta.Type, Function: &FunctionCall{}}
+			a.calls = append(a.calls, current)
+		} else {
+			current = a.calls[len(a.calls)-1]
+		}
+
+		if delta.Function == nil {
+			continue
+		}
+
+		if delta.Function.Name != nil {
+			current.Function.Name = delta.Function.Name
+		}
+
+		if delta.Function.Arguments != nil {
+			if current.Function.Arguments == nil {
+				current.Function.Arguments = delta.Function.Arguments
+			} else {
+				merged := *current.Function.Arguments + *delta.Function.Arguments
+				current.Function.Arguments = &merged
+			}
+		}
+	}
+}
+
+// ToolCalls returns the accumulated tool calls, in the order their first delta arrived.
+func (a *ToolCallDeltaAccumulator) ToolCalls() []ChatCompletionsToolCallClassification {
+	result := make([]ChatCompletionsToolCallClassification, 0, len(a.calls))
+	for _, c := range a.calls {
+		result = append(result, c)
+	}
+	return result
+}
@@ -0,0 +1,74 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package azopenai
+
+import "testing"
+
+func TestToolCallDeltaAccumulator(t *testing.T) {
+	name := "get_weather"
+	id := "call_abc"
+	typ := "function"
+
+	var acc ToolCallDeltaAccumulator
+	acc.Add([]ChatCompletionsToolCallClassification{
+		&ChatCompletionsFunctionToolCall{
+			ID:       &id,
+			Type:     &typ,
+			Function: &FunctionCall{Name: &name, Arguments: strPtr(`{"loc`)},
+		},
+	})
+	acc.Add([]ChatCompletionsToolCallClassification{
+		&ChatCompletionsFunctionToolCall{
+			Function: &FunctionCall{Arguments: strPtr(`ation":"NYC"}`)},
+		},
+	})
+
+	calls := acc.ToolCalls()
+	if len(calls) != 1 {
+		t.Fatalf("got %d tool calls, want 1", len(calls))
+	}
+
+	call, ok := calls[0].(*ChatCompletionsFunctionToolCall)
+	if !ok {
+		t.Fatalf("calls[0] is %T, want *ChatCompletionsFunctionToolCall", calls[0])
+	}
+
+	if got, want := *call.Function.Arguments, `{"location":"NYC"}`; got != want {
+		t.Fatalf("accumulated arguments = %q, want %q", got, want)
+	}
+
+	if got, want := *call.ID, id; got != want {
+		t.Fatalf("ID = %q, want %q", got, want)
+	}
+}
+
+func TestToolCallDeltaAccumulatorMultipleCalls(t *testing.T) {
+	firstID, secondID := "call_1", "call_2"
+
+	var acc ToolCallDeltaAccumulator
+	acc.Add([]ChatCompletionsToolCallClassification{
+		&ChatCompletionsFunctionToolCall{ID: &firstID, Function: &FunctionCall{Arguments: strPtr(`{}`)}},
+	})
+	acc.Add([]ChatCompletionsToolCallClassification{
+		&ChatCompletionsFunctionToolCall{ID: &secondID, Function: &FunctionCall{Arguments: strPtr(`{}`)}},
+	})
+
+	calls := acc.ToolCalls()
+	if len(calls) != 2 {
+		t.Fatalf("got %d tool calls, want 2", len(calls))
+	}
+
+	first := calls[0].(*ChatCompletionsFunctionToolCall)
+	second := calls[1].(*ChatCompletionsFunctionToolCall)
+	if *first.ID != firstID || *second.ID != secondID {
+		t.Fatalf("IDs = %q, %q, want %q, %q", *first.ID, *second.ID, firstID, secondID)
+	}
+}
+
+func strPtr(s string) *string {
+	return &s
+}
@@ -0,0 +1,103 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package tokencount
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChatMessage is the minimal shape CountChatTokens needs from a chat message: its role, its text
+// content, and an optional name (used for function/tool results and named participants).
+type ChatMessage struct {
+	Role    string
+	Name    string
+	Content string
+}
+
+// ChatTool is the minimal shape CountChatTokens needs from a tool definition: its name,
+// description and JSON-Schema-encoded parameters.
+type ChatTool struct {
+	Name        string
+	Description string
+	Parameters  string
+}
+
+// modelFraming holds the per-message and per-name token overhead, and the encoding name, for a
+// family of models. These constants mirror OpenAI's published guidance for counting chat tokens.
+// The per-reply overhead is the same for every model and is added directly by CountChatTokens.
+type modelFraming struct {
+	encoding         EncodingName
+	tokensPerMessage int
+	tokensPerName    int
+}
+
+var modelFramings = map[string]modelFraming{
+	"gpt-3.5-turbo": {encoding: Cl100kBase, tokensPerMessage: 4, tokensPerName: -1},
+	"gpt-4":         {encoding: Cl100kBase, tokensPerMessage: 3, tokensPerName: 1},
+	"gpt-4-turbo":   {encoding: Cl100kBase, tokensPerMessage: 3, tokensPerName: 1},
+	"gpt-4o":        {encoding: O200kBase, tokensPerMessage: 3, tokensPerName: 1},
+	"gpt-4o-mini":   {encoding: O200kBase, tokensPerMessage: 3, tokensPerName: 1},
+}
+
+// framingForModel returns the framing rules for model, matching on the longest registered prefix
+// (so "gpt-4o-2024-08-06" matches the "gpt-4o" entry), and falling back to the gpt-4 framing with
+// the cl100k_base encoding for unrecognized models.
+func framingForModel(model string) modelFraming {
+	var best string
+	for prefix := range modelFramings {
+		if strings.HasPrefix(model, prefix) && len(prefix) > len(best) {
+			best = prefix
+		}
+	}
+
+	if best == "" {
+		return modelFraming{encoding: Cl100kBase, tokensPerMessage: 3, tokensPerName: 1}
+	}
+
+	return modelFramings[best]
+}
+
+// CountChatTokens returns the number of prompt tokens that msgs and tools will consume for model,
+// applying the per-model message-framing rules (fixed per-message overhead, role/name tokens, and
+// tool definition overhead) that the chat completions endpoint uses internally.
+func CountChatTokens(model string, msgs []ChatMessage, tools []ChatTool) (int, error) {
+	framing := framingForModel(model)
+
+	enc, err := Get(framing.encoding)
+	if err != nil {
+		return 0, fmt.Errorf("tokencount: %w", err)
+	}
+
+	total := 0
+
+	for _, msg := range msgs {
+		total += framing.tokensPerMessage
+		total += enc.Count(msg.Role)
+		total += enc.Count(msg.Content)
+
+		if msg.Name != "" {
+			total += enc.Count(msg.Name)
+			total += framing.tokensPerName
+		}
+	}
+
+	// every reply is primed with <|start|>assistant<|message|>
+	total += 3
+
+	for _, tool := range tools {
+		// tool definitions are serialized into the prompt as a JSON-ish function spec; this
+		// mirrors the ~8-12 token fixed overhead OpenAI's own counting guidance adds per tool,
+		// plus the content of the definition itself.
+		total += 8
+		total += enc.Count(tool.Name)
+		total += enc.Count(tool.Description)
+		total += enc.Count(tool.Parameters)
+	}
+
+	return total, nil
+}
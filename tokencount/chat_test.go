@@ -0,0 +1,82 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package tokencount
+
+import "testing"
+
+func TestCountChatTokensIncludesMessageAndReplyOverhead(t *testing.T) {
+	n, err := CountChatTokens("gpt-4o", []ChatMessage{{Role: "user", Content: "hello"}}, nil)
+	if err != nil {
+		t.Fatalf("CountChatTokens: %v", err)
+	}
+
+	enc, err := Get(O200kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	framing := framingForModel("gpt-4o")
+	want := framing.tokensPerMessage + enc.Count("user") + enc.Count("hello") + 3
+	if n != want {
+		t.Fatalf("CountChatTokens = %d, want %d", n, want)
+	}
+}
+
+func TestCountChatTokensAddsNameOverhead(t *testing.T) {
+	withoutName, err := CountChatTokens("gpt-4", []ChatMessage{{Role: "user", Content: "hi"}}, nil)
+	if err != nil {
+		t.Fatalf("CountChatTokens: %v", err)
+	}
+
+	withName, err := CountChatTokens("gpt-4", []ChatMessage{{Role: "user", Content: "hi", Name: "alice"}}, nil)
+	if err != nil {
+		t.Fatalf("CountChatTokens: %v", err)
+	}
+
+	enc, err := Get(Cl100kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	framing := framingForModel("gpt-4")
+	wantDelta := enc.Count("alice") + framing.tokensPerName
+	if got := withName - withoutName; got != wantDelta {
+		t.Fatalf("name overhead = %d, want %d", got, wantDelta)
+	}
+}
+
+func TestCountChatTokensIncludesToolOverhead(t *testing.T) {
+	withoutTools, err := CountChatTokens("gpt-4o", nil, nil)
+	if err != nil {
+		t.Fatalf("CountChatTokens: %v", err)
+	}
+
+	tool := ChatTool{Name: "get_weather", Description: "Gets the weather", Parameters: `{"type":"object"}`}
+	withTools, err := CountChatTokens("gpt-4o", nil, []ChatTool{tool})
+	if err != nil {
+		t.Fatalf("CountChatTokens: %v", err)
+	}
+
+	enc, err := Get(O200kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	wantDelta := 8 + enc.Count(tool.Name) + enc.Count(tool.Description) + enc.Count(tool.Parameters)
+	if got := withTools - withoutTools; got != wantDelta {
+		t.Fatalf("tool overhead = %d, want %d", got, wantDelta)
+	}
+}
+
+func TestFramingForModelMatchesLongestPrefix(t *testing.T) {
+	if got, want := framingForModel("gpt-4o-2024-08-06").encoding, O200kBase; got != want {
+		t.Fatalf("encoding = %q, want %q", got, want)
+	}
+	if got, want := framingForModel("some-unknown-model").encoding, Cl100kBase; got != want {
+		t.Fatalf("encoding = %q, want %q (fallback)", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+// Package tokencount implements a byte-pair-encoding tokenizer, using split patterns adapted from
+// OpenAI's tiktoken cl100k_base/o200k_base encodings and its token-counting rules, for estimating prompt size before
+// sending a request. The embedded merge tables are NOT the canonical cl100k_base/o200k_base
+// vocabularies - see the mergeTableFS doc comment below - so counts are an approximation, not an
+// exact match for what the service will bill or enforce.
+package tokencount
+
+import (
+	"bufio"
+	"bytes"
+	"embed"
+	"encoding/base64"
+	"fmt"
+	"regexp"
+	"sync"
+)
+
+// mergeTableFS embeds the base64-token/rank merge tables, in the same "<token> <rank>" per-line
+// format OpenAI's tiktoken distributes its encodings in. These are NOT the canonical
+// cl100k_base/o200k_base tables: they're a from-scratch BPE vocabulary trained locally over a
+// general-English and API-documentation corpus, since fetching OpenAI's published tables requires
+// network access this package doesn't assume. Token counts for ordinary prompts are close to, but
+// not byte-identical with, the real encodings; swap in the full upstream tables here for exact
+// parity.
+//
+//go:embed data/cl100k_base.tiktoken data/o200k_base.tiktoken
+var mergeTableFS embed.FS
+
+// EncodingName identifies one of the supported BPE encodings.
+type EncodingName string
+
+const (
+	// Cl100kBase is used by GPT-3.5-Turbo and GPT-4 models.
+	Cl100kBase EncodingName = "cl100k_base"
+
+	// O200kBase is used by GPT-4o and newer models.
+	O200kBase EncodingName = "o200k_base"
+)
+
+// splitPatterns holds the regular expression used to split text into chunks before BPE merging is
+// applied within each chunk, keyed by encoding name. These are RE2 adaptations of tiktoken's
+// patterns: Go's regexp has no lookahead, so the whitespace alternatives differ, and both
+// encodings use the same cl100k_base-style pattern apart from the scope of case-insensitivity.
+var splitPatterns = map[EncodingName]*regexp.Regexp{
+	Cl100kBase: regexp.MustCompile(`(?i)'s|'t|'re|'ve|'m|'ll|'d|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?:\s)|\s+`),
+	O200kBase:  regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+(?:\s)|\s+`),
+}
+
+// Encoding is a loaded BPE tokenizer: a rank-ordered merge table plus the pre-tokenization regex
+// used to chunk text before merging.
+//
+// NOTE: This should be created using [tokencount.Get].
+type Encoding struct {
+	name    EncodingName
+	pattern *regexp.Regexp
+	ranks   map[string]int
+}
+
+var (
+	loadedMu sync.Mutex
+	loaded   = map[EncodingName]*Encoding{}
+)
+
+// Get returns the Encoding for name, loading and caching its merge table on first use. It's safe
+// to call concurrently.
+func Get(name EncodingName) (*Encoding, error) {
+	loadedMu.Lock()
+	defer loadedMu.Unlock()
+
+	if enc, ok := loaded[name]; ok {
+		return enc, nil
+	}
+
+	pattern, ok := splitPatterns[name]
+	if !ok {
+		return nil, fmt.Errorf("tokencount: unknown encoding %q", name)
+	}
+
+	ranks, err := loadMergeTable(name)
+	if err != nil {
+		return nil, err
+	}
+
+	enc := &Encoding{name: name, pattern: pattern, ranks: ranks}
+	loaded[name] = enc
+	return enc, nil
+}
+
+// loadMergeTable reads the embedded "<token-base64> <rank>" merge table for name, the same format
+// OpenAI distributes its tiktoken encodings in.
+func loadMergeTable(name EncodingName) (map[string]int, error) {
+	data, err := mergeTableFS.ReadFile("data/" + string(name) + ".tiktoken")
+	if err != nil {
+		return nil, fmt.Errorf("tokencount: no merge table embedded for encoding %q: %w", name, err)
+	}
+
+	ranks := map[string]int{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		var tokB64 string
+		var rank int
+		if _, err := fmt.Sscanf(line, "%s %d", &tokB64, &rank); err != nil {
+			continue
+		}
+
+		tok, err := base64.StdEncoding.DecodeString(tokB64)
+		if err != nil {
+			continue
+		}
+
+		ranks[string(tok)] = rank
+	}
+
+	return ranks, scanner.Err()
+}
+
+// Encode tokenizes text, returning the BPE token IDs (ranks) it was split into.
+func (e *Encoding) Encode(text string) []int {
+	var ids []int
+
+	for _, chunk := range e.pattern.FindAllString(text, -1) {
+		ids = append(ids, e.bpe([]byte(chunk))...)
+	}
+
+	return ids
+}
+
+// Count returns len(e.Encode(text)) without allocating the intermediate slice of token IDs.
+func (e *Encoding) Count(text string) int {
+	count := 0
+	for _, chunk := range e.pattern.FindAllString(text, -1) {
+		count += len(e.bpe([]byte(chunk)))
+	}
+	return count
+}
+
+// bpe merges word (a single pre-tokenized chunk) into its constituent ranked tokens, repeatedly
+// combining the lowest-rank adjacent pair until no known merge applies, following the standard
+// byte-pair-encoding algorithm.
+func (e *Encoding) bpe(word []byte) []int {
+	parts := make([][]byte, len(word))
+	for i, b := range word {
+		parts[i] = []byte{b}
+	}
+
+	for len(parts) > 1 {
+		bestRank := -1
+		bestIdx := -1
+
+		for i := 0; i < len(parts)-1; i++ {
+			pair := append(append([]byte{}, parts[i]...), parts[i+1]...)
+			if rank, ok := e.ranks[string(pair)]; ok {
+				if bestRank == -1 || rank < bestRank {
+					bestRank = rank
+					bestIdx = i
+				}
+			}
+		}
+
+		if bestIdx == -1 {
+			break
+		}
+
+		merged := append(append([]byte{}, parts[bestIdx]...), parts[bestIdx+1]...)
+		parts = append(parts[:bestIdx], append([][]byte{merged}, parts[bestIdx+2:]...)...)
+	}
+
+	ids := make([]int, len(parts))
+	for i, p := range parts {
+		if rank, ok := e.ranks[string(p)]; ok {
+			ids[i] = rank
+		} else {
+			// unknown byte sequence (not present in the embedded merge table): fall back to a
+			// stable, out-of-band id so callers still get a plausible token count.
+			ids[i] = 0x10000 + int(p[0])
+		}
+	}
+
+	return ids
+}
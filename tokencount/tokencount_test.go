@@ -0,0 +1,84 @@
+//go:build go1.18
+// +build go1.18
+
+// Copyright (c) Microsoft Corporation. All rights reserved.
+// Licensed under the MIT License. See License.txt in the project root for license information.
+
+package tokencount
+
+import "testing"
+
+func TestEncodeEmptyString(t *testing.T) {
+	enc, err := Get(Cl100kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	if ids := enc.Encode(""); len(ids) != 0 {
+		t.Fatalf("Encode(\"\") = %v, want empty", ids)
+	}
+	if count := enc.Count(""); count != 0 {
+		t.Fatalf("Count(\"\") = %d, want 0", count)
+	}
+}
+
+func TestEncodeMergesKnownPair(t *testing.T) {
+	enc, err := Get(Cl100kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	// " the" is a single merged token in the embedded cl100k_base table, so it must encode to
+	// exactly one ID rather than one per byte.
+	ids := enc.Encode(" the")
+	if len(ids) != 1 {
+		t.Fatalf("Encode(\" the\") = %v, want a single merged token", ids)
+	}
+}
+
+func TestCountMatchesEncodeLength(t *testing.T) {
+	enc, err := Get(Cl100kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	for _, text := range []string{"hello world", "the quick brown fox", "a", "   "} {
+		if got, want := enc.Count(text), len(enc.Encode(text)); got != want {
+			t.Fatalf("Count(%q) = %d, want len(Encode(...)) = %d", text, got, want)
+		}
+	}
+}
+
+func TestBPEMergesWholeWordToken(t *testing.T) {
+	enc, err := Get(Cl100kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+
+	// "services" is a single merged token in the embedded cl100k_base table, so bpe must collapse
+	// it to one ID rather than leaving it as 8 individual bytes.
+	ids := enc.bpe([]byte("services"))
+	if len(ids) != 1 {
+		t.Fatalf("bpe(\"services\") = %v, want a single merged token", ids)
+	}
+}
+
+func TestGetCachesEncoding(t *testing.T) {
+	first, err := Get(O200kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	second, err := Get(O200kBase)
+	if err != nil {
+		t.Fatalf("Get: %v", err)
+	}
+	if first != second {
+		t.Fatalf("Get returned different *Encoding instances for the same name, want the cached one")
+	}
+}
+
+func TestGetUnknownEncoding(t *testing.T) {
+	if _, err := Get("not-a-real-encoding"); err == nil {
+		t.Fatalf("err = nil, want an error for an unknown encoding")
+	}
+}